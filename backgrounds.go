@@ -0,0 +1,319 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	// Starfield parameters
+	starNear   = 1.0    // Depth at which a star passes the camera and respawns
+	starFar    = 1000.0 // Depth of newly spawned stars
+	starSpread = 1000.0 // Half extent of the spawn area, enough to fill the canvas at starFar
+
+	// Tunnel parameters
+	tunnelDepth = 32.0 // Texture lengths between the tunnel mouth and a radius of one pixel
+	tunnelSpin  = 0.05 // Turns per second of the tunnel around its axis
+
+	// Rotozoom parameters
+	rotozoomZoomMin = 0.5  // Smallest magnification of the texture
+	rotozoomZoomMax = 3.0  // Largest magnification of the texture
+	rotozoomDrift   = 40.0 // Texture pixels per second the view center travels
+
+	// Twister parameters
+	twisterRadius    = 48.0 // Half width of the ribbon seen flat on
+	twisterWaveFreq  = 0.01 // Radians of the twist wave per line
+	twisterWaveTwist = 2.0  // Largest extra rotation added by the twist wave in radians
+	twisterSway      = 120  // Horizontal travel of the column in pixels
+	twisterSwaySpeed = 0.7  // Radians per second of the sway
+)
+
+// Background selects the effect drawn behind the main demo
+type Background int
+
+const (
+	BackgroundPlasma Background = iota
+	BackgroundStarfield
+	BackgroundFire
+	BackgroundTunnel
+	BackgroundRotozoom
+	backgroundCount
+)
+
+// backgrounds maps the configuration names of the backgrounds
+var backgrounds = map[string]Background{
+	"plasma":    BackgroundPlasma,
+	"starfield": BackgroundStarfield,
+	"fire":      BackgroundFire,
+	"tunnel":    BackgroundTunnel,
+	"rotozoom":  BackgroundRotozoom,
+}
+
+// Starfield is a field of stars flying towards the camera
+type Starfield struct {
+	stars []Vector3
+	speed float64 // Depth units per frame
+	rng   *rand.Rand
+}
+
+// NewStarfield scatters count stars through the whole depth range
+func NewStarfield(count int, speed float64, rng *rand.Rand) *Starfield {
+	s := &Starfield{
+		stars: make([]Vector3, count),
+		speed: speed,
+		rng:   rng,
+	}
+	for i := range s.stars {
+		s.spawn(i)
+		s.stars[i].Z = starNear + s.rng.Float64()*(starFar-starNear)
+	}
+	return s
+}
+
+// spawn places star i at a random position on the far plane
+func (s *Starfield) spawn(i int) {
+	s.stars[i] = Vector3{
+		X: (s.rng.Float64()*2 - 1) * starSpread,
+		Y: (s.rng.Float64()*2 - 1) * starSpread,
+		Z: starFar,
+	}
+}
+
+// Update moves the stars towards the camera, respawning those that pass it
+func (s *Starfield) Update() {
+	for i := range s.stars {
+		s.stars[i].Z -= s.speed
+		if s.stars[i].Z < starNear {
+			s.spawn(i)
+		}
+	}
+}
+
+// Draw projects the stars onto dst with the given field of view, like the cube.
+// Nearer stars are bigger and brighter.
+func (s *Starfield) Draw(dst *ebiten.Image, fov float64) {
+	cx := float64(dst.Bounds().Dx()) / 2
+	cy := float64(dst.Bounds().Dy()) / 2
+
+	for _, star := range s.stars {
+		x := cx + star.X*fov/star.Z
+		y := cy + star.Y*fov/star.Z
+		if x < 0 || y < 0 || x >= cx*2 || y >= cy*2 {
+			continue
+		}
+
+		nearness := 1 - star.Z/starFar
+		size := float32(1 + 2*nearness)
+		v := uint8(64 + 191*nearness)
+		vector.DrawFilledRect(dst, float32(x), float32(y), size, size, color.RGBA{v, v, v, 255}, false)
+	}
+}
+
+// FireEffect is the classic fire: heat seeded on the bottom row rises, spreads and cools
+type FireEffect struct {
+	width     int
+	height    int
+	heat      []float64 // From 0 to 255, row by row
+	pixels    []byte
+	lut       [256]color.RGBA
+	buffer    *ebiten.Image
+	intensity float64 // Share of hot pixels seeded on the bottom row
+	cooling   float64 // Heat lost per row
+	rng       *rand.Rand
+}
+
+// NewFireEffect creates a cold fire rendering into buffer
+func NewFireEffect(buffer *ebiten.Image, intensity, cooling float64, rng *rand.Rand) *FireEffect {
+	w, h := buffer.Bounds().Dx(), buffer.Bounds().Dy()
+	f := &FireEffect{
+		width:     w,
+		height:    h,
+		heat:      make([]float64, w*h),
+		pixels:    make([]byte, 4*w*h),
+		buffer:    buffer,
+		intensity: intensity,
+		cooling:   cooling,
+		rng:       rng,
+	}
+
+	// Black through red and yellow to white, the ramp of the fire plasma palette
+	for i := range f.lut {
+		t := float64(i) / 255
+		f.lut[i] = color.RGBA{uint8(clamp01(t*3) * 255), uint8(clamp01(t*3-1) * 255), uint8(clamp01(t*3-2) * 255), 255}
+	}
+	return f
+}
+
+// Update seeds the bottom row, moves the heat up one row and uploads the frame
+func (f *FireEffect) Update() {
+	bottom := (f.height - 1) * f.width
+	for x := 0; x < f.width; x++ {
+		f.heat[bottom+x] = 0
+		if f.rng.Float64() < f.intensity {
+			f.heat[bottom+x] = 255
+		}
+	}
+
+	// Each pixel takes the average of the three below it and the one two rows down, minus the cooling
+	for y := 0; y < f.height-1; y++ {
+		below := (y + 1) * f.width
+		below2 := min(y+2, f.height-1) * f.width
+		for x := 0; x < f.width; x++ {
+			left := max(x-1, 0)
+			right := min(x+1, f.width-1)
+			v := (f.heat[below+left]+f.heat[below+x]+f.heat[below+right]+f.heat[below2+x])/4 - f.cooling
+			f.heat[y*f.width+x] = math.Max(0, v)
+		}
+	}
+
+	for i, v := range f.heat {
+		c := f.lut[int(v)]
+		f.pixels[i*4] = c.R
+		f.pixels[i*4+1] = c.G
+		f.pixels[i*4+2] = c.B
+		f.pixels[i*4+3] = 255
+	}
+	f.buffer.WritePixels(f.pixels)
+}
+
+// TunnelEffect flies down a textured tunnel. Each pixel's distance into the tunnel and
+// angle around it are computed once; a frame only offsets them and samples the texture.
+type TunnelEffect struct {
+	width    int
+	height   int
+	distance []int   // Texture row per pixel, before the scroll offset
+	angle    []int   // Texture column per pixel, before the spin offset
+	shade    []uint8 // Brightness per pixel, darker towards the far end
+	texture  *image.RGBA
+	pixels   []byte
+	buffer   *ebiten.Image
+	speed    float64 // Texture lengths per second
+	twist    float64 // Turns per texture length
+	time     float64
+}
+
+// NewTunnelEffect builds the lookup tables for a tunnel rendering into buffer
+func NewTunnelEffect(buffer *ebiten.Image, texture *image.RGBA, speed, twist float64) *TunnelEffect {
+	w, h := buffer.Bounds().Dx(), buffer.Bounds().Dy()
+	texW, texH := texture.Bounds().Dx(), texture.Bounds().Dy()
+	t := &TunnelEffect{
+		width:    w,
+		height:   h,
+		distance: make([]int, w*h),
+		angle:    make([]int, w*h),
+		shade:    make([]uint8, w*h),
+		texture:  texture,
+		pixels:   make([]byte, 4*w*h),
+		buffer:   buffer,
+		speed:    speed,
+		twist:    twist,
+	}
+
+	maxRadius := math.Hypot(float64(w)/2, float64(h)/2)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dx := float64(x) - float64(w)/2
+			dy := float64(y) - float64(h)/2
+			r := math.Max(math.Hypot(dx, dy), 1)
+
+			i := y*w + x
+			t.distance[i] = int(tunnelDepth * float64(texH) / r)
+			t.angle[i] = int(float64(texW) * (math.Atan2(dy, dx)/(2*math.Pi) + 0.5))
+			t.shade[i] = uint8(255 * math.Min(1, 2*r/maxRadius))
+		}
+	}
+	return t
+}
+
+// Update moves down the tunnel and uploads the frame
+func (t *TunnelEffect) Update() {
+	t.time += animationStep
+
+	texW, texH := t.texture.Bounds().Dx(), t.texture.Bounds().Dy()
+	shiftV := int(t.time * t.speed * float64(texH))
+	shiftU := int(t.time * tunnelSpin * float64(texW))
+	twist := t.twist * float64(texW) / float64(texH)
+
+	for i, d := range t.distance {
+		u := ((t.angle[i]+shiftU+int(twist*float64(d)))%texW + texW) % texW
+		v := ((d+shiftV)%texH + texH) % texH
+		src := t.texture.PixOffset(u, v)
+		s := uint16(t.shade[i])
+		t.pixels[i*4] = uint8(uint16(t.texture.Pix[src]) * s / 255)
+		t.pixels[i*4+1] = uint8(uint16(t.texture.Pix[src+1]) * s / 255)
+		t.pixels[i*4+2] = uint8(uint16(t.texture.Pix[src+2]) * s / 255)
+		t.pixels[i*4+3] = 255
+	}
+	t.buffer.WritePixels(t.pixels)
+}
+
+// drawRotozoom fills the canvas with the texture rotated and zoomed around a drifting
+// center. The texture repeats, so the plane looks infinite.
+func (g *Game) drawRotozoom() {
+	w := float64(g.stCanvas.Bounds().Dx())
+	h := float64(g.stCanvas.Bounds().Dy())
+
+	angle := g.demoTime * g.cfg.RotozoomSpeed
+	zoom := rotozoomZoomMin + (rotozoomZoomMax-rotozoomZoomMin)*(0.5-0.5*math.Cos(2*math.Pi*g.demoTime*g.cfg.RotozoomZoom))
+	cos, sin := math.Cos(angle)/zoom, math.Sin(angle)/zoom
+	centerU := float64(g.texture.Bounds().Dx())/2 + g.demoTime*rotozoomDrift
+	centerV := float64(g.texture.Bounds().Dy()) / 2
+
+	// One quad over the whole canvas, its corners mapped to the rotated texture
+	vertices := make([]ebiten.Vertex, 4)
+	for i, c := range [4][2]float64{{0, 0}, {w, 0}, {0, h}, {w, h}} {
+		dx, dy := c[0]-w/2, c[1]-h/2
+		vertices[i] = ebiten.Vertex{
+			DstX:   float32(c[0]),
+			DstY:   float32(c[1]),
+			SrcX:   float32(centerU + dx*cos - dy*sin),
+			SrcY:   float32(centerV + dx*sin + dy*cos),
+			ColorR: 1,
+			ColorG: 1,
+			ColorB: 1,
+			ColorA: 1,
+		}
+	}
+
+	op := &ebiten.DrawTrianglesOptions{Address: ebiten.AddressRepeat, Filter: ebiten.FilterLinear}
+	g.stCanvas.DrawTriangles(vertices, []uint16{0, 1, 2, 1, 3, 2}, g.texture, op)
+}
+
+// drawTwister draws a textured square ribbon turning around a vertical axis. Each line
+// turns by a little more than the one above, and the faces facing the camera are drawn
+// as texture rows stretched between their edges.
+func (g *Game) drawTwister() {
+	texW := g.texture.Bounds().Dx()
+	texH := g.texture.Bounds().Dy()
+	centerX := float64(g.stCanvas.Bounds().Dx())/2 + math.Sin(g.demoTime*twisterSwaySpeed)*twisterSway
+	top := (g.stCanvas.Bounds().Dy() - g.cfg.TwisterHeight) / 2
+
+	for y := 0; y < g.cfg.TwisterHeight; y++ {
+		angle := g.demoTime*g.cfg.TwisterSpeed + math.Sin(float64(y)*twisterWaveFreq+g.demoTime)*twisterWaveTwist
+		srcRow := g.texture.SubImage(image.Rect(0, y%texH, texW, y%texH+1)).(*ebiten.Image)
+
+		for face := 0; face < 4; face++ {
+			x0 := centerX + math.Sin(angle+float64(face)*math.Pi/2)*twisterRadius
+			x1 := centerX + math.Sin(angle+float64(face+1)*math.Pi/2)*twisterRadius
+			if x1 <= x0 {
+				// Facing away
+				continue
+			}
+
+			// Faces turned towards the camera are wider and lit more
+			light := float32((x1 - x0) / (twisterRadius * math.Sqrt2))
+			op := g.drawOp
+			op.GeoM.Reset()
+			op.ColorScale.Reset()
+			op.GeoM.Scale((x1-x0)/float64(texW), 1)
+			op.GeoM.Translate(x0, float64(top+y))
+			op.ColorScale.Scale(light, light, light, 1)
+			g.stCanvas.DrawImage(srcRow, op)
+		}
+	}
+}
@@ -0,0 +1,640 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// supportedSampleRates lists the audio sample rates accepted by -samplerate
+var supportedSampleRates = []int{11025, 22050, 32000, 44100, 48000}
+
+// validateSampleRate returns a usable sample rate, warning about unsupported or degraded ones
+func validateSampleRate(rate int) int {
+	supported := false
+	for _, r := range supportedSampleRates {
+		if r == rate {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		log.Printf("Unsupported sample rate %d Hz, using %d Hz (supported: %v)", rate, defaultSampleRate, supportedSampleRates)
+		return defaultSampleRate
+	}
+
+	if rate < defaultSampleRate {
+		log.Printf("Warning: YM output at %d Hz loses high harmonics and may sound muffled or aliased", rate)
+	}
+	return rate
+}
+
+// Starting scenes accepted by -scene
+const (
+	sceneIntro = "intro"
+	sceneDemo  = "demo"
+)
+
+// configPath is the optional configuration file read at startup and written with F2
+const configPath = "config.json"
+
+// Config holds the demo settings, loaded from configPath and overridden by command-line flags
+type Config struct {
+	WindowWidth     int     `json:"window_width"`
+	WindowHeight    int     `json:"window_height"`
+	WindowTitle     string  `json:"window_title"`
+	Fullscreen      bool    `json:"fullscreen"`
+	VSync           bool    `json:"vsync"`
+	Volume          float64 `json:"volume"`      // Music volume from 0 to 1
+	StartScene      string  `json:"start_scene"` // sceneIntro or sceneDemo
+	SampleRate      int     `json:"sample_rate"`
+	LogoCount       int     `json:"logo_count"`
+	Debug           bool    `json:"debug"`
+	ShowProgress    bool    `json:"show_progress"`    // Music progress bar under the main demo
+	ShaderPath      string  `json:"shader_path"`      // External CRT shader, empty for the built-in one
+	PhosphorMask    float64 `json:"phosphor_mask"`    // Strength of the aperture-grille mask over the picture, 0 to disable
+	Bloom           bool    `json:"bloom"`            // Glow around the bright areas of the main demo
+	Brightness      float64 `json:"brightness"`       // Multiplier of the final image, 1 to leave it unchanged
+	Gamma           float64 `json:"gamma"`            // Gamma correction of the final image, above 1 to lift the shadows
+	AssetsDir       string  `json:"assets_dir"`       // Directory of replacement images, empty for the embedded ones
+	NoAudio         bool    `json:"no_audio"`         // Run silently, without the music or its sync
+	Deterministic   bool    `json:"deterministic"`    // One animation step per Update, ignoring real time, for recording
+	Seed            int64   `json:"seed"`             // Seed of the random effects, 0 for a new one on each run
+	RenderUnfocused bool    `json:"render_unfocused"` // Keep the effects running while the window is unfocused or minimized
+
+	// Attract mode, looping back to the intro for unattended runs
+	Attract         bool    `json:"attract"`
+	AttractDuration float64 `json:"attract_duration"` // Seconds of main demo before looping, 0 to wait for the end of the tune
+
+	// Animation tuning
+	Transition        string        `json:"transition"`        // "cut", "black", "dissolve" or "glitch"
+	TransitionFrames  int           `json:"transition_frames"` // Length of scene transitions
+	PlasmaSpeed       float64       `json:"plasma_speed"`
+	PlasmaFullRes     bool          `json:"plasma_full_res"`    // Render the plasma at the canvas resolution instead of half
+	IntroScrollSpeed  int           `json:"intro_scroll_speed"` // Pixels per frame
+	ScrollSpeed       float64       `json:"scroll_speed"`       // Pixels per frame
+	CubeRotationSpeed Vector3       `json:"cube_rotation_speed"`
+	RainbowSpeed      float64       `json:"rainbow_speed"`      // Scroller rainbow hue cycles per second
+	ScrollMode        string        `json:"scroll_mode"`        // "wave", "bounce" or "typewriter"
+	ScrollPulse       bool          `json:"scroll_pulse"`       // Pulse the character sizes in bounce mode
+	TypewriterSpeed   float64       `json:"typewriter_speed"`   // Characters revealed per second
+	ScrollReverse     bool          `json:"scroll_reverse"`     // Scroll the main text left to right
+	ScrollWave        []WaveSegment `json:"scroll_wave"`        // Horizontal wave of the scroller, one offset per line
+	ScrollGradient    bool          `json:"scroll_gradient"`    // Vertical color gradient on the scroller
+	ScrollFringe      bool          `json:"scroll_fringe"`      // Red and blue fringes on the wave scroller, widening with the wave
+	ReflectionOpacity float64       `json:"reflection_opacity"` // Opacity of the wave scroller's floor reflection, 0 to hide it
+	ReflectionHeight  int           `json:"reflection_height"`  // Height of the reflection in pixels, fading out downwards
+	GradientTop       [3]uint8      `json:"gradient_top"`       // RGB color at the top of the scroller
+	GradientBottom    [3]uint8      `json:"gradient_bottom"`    // RGB color at the bottom of the scroller
+	LogoAmplitude     float64       `json:"logo_amplitude"`
+	LogoSpeed         float64       `json:"logo_speed"`
+	ShakeMagnitude    float64       `json:"shake_magnitude"` // Pixels of screen shake on each beat, 0 to disable
+	ShakeDecay        float64       `json:"shake_decay"`     // Share of the shake kept from one frame to the next
+
+	// Background effects
+	Background    string  `json:"background"` // "plasma", "starfield", "fire", "tunnel" or "rotozoom"
+	StarCount     int     `json:"star_count"`
+	StarSpeed     float64 `json:"star_speed"`     // Depth units per frame
+	FireIntensity float64 `json:"fire_intensity"` // Share of hot pixels seeded on the bottom row, from 0 to 1
+	FireCooling   float64 `json:"fire_cooling"`   // Heat lost per row, out of 255
+	TunnelSpeed   float64 `json:"tunnel_speed"`   // Texture lengths per second
+	TunnelTwist   float64 `json:"tunnel_twist"`   // Turns per texture length into the tunnel
+	RotozoomSpeed float64 `json:"rotozoom_speed"` // Rotation in radians per second
+	RotozoomZoom  float64 `json:"rotozoom_zoom"`  // Zoom in and out cycles per second
+
+	// Copper bars behind the TEAMG1 logo
+	CopperBars   bool       `json:"copper_bars"`
+	CopperCount  int        `json:"copper_count"`
+	CopperColors [][3]uint8 `json:"copper_colors"` // RGB colors, used in turn by the bars
+	CopperSpeed  float64    `json:"copper_speed"`  // Radians per second
+
+	// Twister column
+	Twister       bool    `json:"twister"`
+	TwisterSpeed  float64 `json:"twister_speed"`  // Radians per second
+	TwisterHeight int     `json:"twister_height"` // Column height in pixels
+
+	// Music output
+	LowPass       bool    `json:"low_pass"`        // Soften the chip sound like the ST's output stage
+	LowPassCutoff float64 `json:"low_pass_cutoff"` // Filter cutoff in Hz
+	StereoWidth   float64 `json:"stereo_width"`    // Pseudo-stereo spread, from 0 (mono) to 1
+
+	// Key of each action, by action name
+	Keys map[string]ebiten.Key `json:"keys"`
+
+	// Scroll texts
+	IntroText  string `json:"intro_text"`
+	ScrollText string `json:"scroll_text"`
+
+	// Looping ticker under the intro scroll
+	IntroTicker      string `json:"intro_ticker"`       // Ticker text, empty to hide it
+	IntroTickerSpeed int    `json:"intro_ticker_speed"` // Pixels per frame
+	IntroTickerY     int    `json:"intro_ticker_y"`     // Top of the ticker on the screen
+}
+
+// DefaultConfig returns the built-in settings
+func DefaultConfig() Config {
+	return Config{
+		WindowWidth:  screenWidth,
+		WindowHeight: screenHeight,
+		WindowTitle:  "TEAMG1 Demo - A Tribute to the Golden Age",
+		VSync:        true,
+		Volume:       0.7,
+		StartScene:   sceneIntro,
+		SampleRate:   defaultSampleRate,
+		LogoCount:    defaultLogoCount,
+		Brightness:   1,
+		Gamma:        1,
+
+		Transition:        "black",
+		TransitionFrames:  int(math.Round(1 / fadeSpeed)),
+		PlasmaSpeed:       plasmaSpeed,
+		IntroScrollSpeed:  6,
+		ScrollSpeed:       2.0,
+		ScrollMode:        "wave",
+		TypewriterSpeed:   12,
+		CubeRotationSpeed: Vector3{X: 0.02, Y: 0.03, Z: 0.01},
+		RainbowSpeed:      0.5,
+		ScrollWave: []WaveSegment{
+			{Count: 389, Terms: []WaveTerm{{Amplitude: 20, FreqDeg: 7}, {Amplitude: 30, FreqDeg: 3, PhaseDeg: 90}}},
+			{Count: 120, Terms: []WaveTerm{{Amplitude: 4, FreqDeg: 72}}},
+			{Count: 68, Terms: []WaveTerm{{Amplitude: 40, FreqDeg: 8}}},
+		},
+		GradientTop:       [3]uint8{255, 255, 160},
+		GradientBottom:    [3]uint8{255, 80, 0},
+		ReflectionOpacity: 0.3,
+		ReflectionHeight:  40,
+		LogoAmplitude:     defaultLogoAmplitude,
+		LogoSpeed:         defaultLogoSpeed,
+		ShakeMagnitude:    4,
+		ShakeDecay:        0.8,
+
+		Background:    "plasma",
+		StarCount:     300,
+		StarSpeed:     8,
+		FireIntensity: 0.6,
+		FireCooling:   1.5,
+		TunnelSpeed:   1,
+		TunnelTwist:   0.1,
+		RotozoomSpeed: 0.5,
+		RotozoomZoom:  0.1,
+
+		CopperCount: 7,
+		CopperColors: [][3]uint8{
+			{255, 0, 0}, {255, 128, 0}, {255, 255, 0}, {0, 255, 0},
+			{0, 255, 255}, {0, 64, 255}, {255, 0, 255},
+		},
+		CopperSpeed: 2,
+
+		TwisterSpeed:  1.5,
+		TwisterHeight: stCanvasHeight,
+
+		LowPassCutoff: 7000,
+
+		Keys: defaultKeys(),
+
+		IntroText: "C'EST MERCREDI...     JE REPETE, C'EST MERCREDI ET LE MERCREDI...",
+		ScrollText: "C'EST TEAMG1 A 16H00 SUR GAMEONE POUR TOUS LES GAMERS, LES GEEKS ET LES NERDS.     " +
+			"ENCORE UN BON APRES MIDI AVEC TOUTE L'EQUIPE DE TEAMG1! VIVEMENT 16H00",
+
+		IntroTicker:      "TEAMG1 SUR GAMEONE... SALUT A TOUS LES GAMERS, LES GEEKS ET LES NERDS!",
+		IntroTickerSpeed: 9,
+		IntroTickerY:     screenHeight - int(fontHeight*(creditsFontScale+tickerFontScale)) - 16,
+	}
+}
+
+// LoadConfig reads a configuration file over the defaults.
+// A missing file is not an error, and fields absent from the file keep their default values.
+func LoadConfig(path string) (Config, error) {
+	cfg := DefaultConfig()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return DefaultConfig(), fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Save writes the configuration as indented JSON
+func (c Config) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// bindFlags registers command-line flags writing into the config, using its current values as defaults
+func (c *Config) bindFlags(fs *flag.FlagSet) {
+	fs.IntVar(&c.WindowWidth, "width", c.WindowWidth, "window width in pixels")
+	fs.IntVar(&c.WindowHeight, "height", c.WindowHeight, "window height in pixels")
+	fs.StringVar(&c.WindowTitle, "title", c.WindowTitle, "window title")
+	fs.BoolVar(&c.Fullscreen, "fullscreen", c.Fullscreen, "start in fullscreen mode")
+	fs.BoolVar(&c.VSync, "vsync", c.VSync, "synchronize rendering with the display refresh")
+	fs.Float64Var(&c.Volume, "volume", c.Volume, "music volume from 0 to 1")
+	fs.StringVar(&c.StartScene, "scene", c.StartScene, "starting scene (intro or demo)")
+	fs.StringVar(&c.Transition, "transition", c.Transition, "scene transition (cut, black, dissolve or glitch)")
+	fs.IntVar(&c.TransitionFrames, "transition-frames", c.TransitionFrames, "length of scene transitions in frames")
+	fs.IntVar(&c.SampleRate, "samplerate", c.SampleRate, "audio sample rate in Hz")
+	fs.IntVar(&c.LogoCount, "logos", c.LogoCount, "number of logos in the spiral (at least 1)")
+	fs.BoolVar(&c.ShowProgress, "progress", c.ShowProgress, "show the music progress bar in the main demo (toggle with F4)")
+	fs.BoolVar(&c.Debug, "debug", c.Debug, "show the FPS and frame time overlay (toggle with F3)")
+	fs.StringVar(&c.IntroText, "intro", c.IntroText, "intro scroll text")
+	fs.IntVar(&c.IntroScrollSpeed, "intro-speed", c.IntroScrollSpeed, "intro scroll speed in pixels per frame")
+	fs.StringVar(&c.IntroTicker, "ticker", c.IntroTicker, "intro bottom ticker text (empty to hide it)")
+	fs.IntVar(&c.IntroTickerSpeed, "ticker-speed", c.IntroTickerSpeed, "intro bottom ticker speed in pixels per frame")
+	fs.StringVar(&c.ScrollText, "scroll", c.ScrollText, "main demo scroll text")
+	fs.BoolVar(&c.PlasmaFullRes, "plasma-full", c.PlasmaFullRes, "render the plasma at full canvas resolution (crisper, slower)")
+	fs.StringVar(&c.Background, "background", c.Background, "main demo background (plasma, starfield, fire, tunnel or rotozoom)")
+	fs.BoolVar(&c.CopperBars, "copper", c.CopperBars, "show copper bars behind the logo")
+	fs.BoolVar(&c.Twister, "twister", c.Twister, "show the twister column")
+	fs.StringVar(&c.ScrollMode, "scroll-mode", c.ScrollMode, "main text mode (wave, bounce or typewriter)")
+	fs.Float64Var(&c.ShakeMagnitude, "shake", c.ShakeMagnitude, "screen shake on each beat in pixels (0 to disable)")
+	fs.BoolVar(&c.ScrollGradient, "gradient", c.ScrollGradient, "color the scroll text with a vertical gradient")
+	fs.Float64Var(&c.ReflectionOpacity, "reflection", c.ReflectionOpacity, "opacity of the scroller's floor reflection from 0 (hidden) to 1")
+	fs.BoolVar(&c.ScrollFringe, "fringe", c.ScrollFringe, "split the wave scroller into red and blue fringes following the wave (toggle with J)")
+	fs.BoolVar(&c.NoAudio, "noaudio", c.NoAudio, "run without music")
+	fs.Float64Var(&c.StereoWidth, "stereo-width", c.StereoWidth, "pseudo-stereo spread of the music from 0 (mono) to 1")
+	fs.BoolVar(&c.LowPass, "lowpass", c.LowPass, "soften the music with a low-pass filter (toggle with L)")
+	fs.BoolVar(&c.Deterministic, "deterministic", c.Deterministic, "advance one animation step per tick, ignoring real time (for recording)")
+	fs.BoolVar(&c.RenderUnfocused, "render-unfocused", c.RenderUnfocused, "keep the effects running while the window is unfocused or minimized")
+	fs.BoolVar(&c.Attract, "attract", c.Attract, "loop back to the intro when the tune ends, for unattended runs")
+	fs.Float64Var(&c.AttractDuration, "attract-duration", c.AttractDuration, "with -attract, seconds of main demo before looping back (0 waits for the end of the tune)")
+	fs.Int64Var(&c.Seed, "seed", c.Seed, "seed of the random effects, 0 for a time-based one (with -deterministic, a fixed seed replays identical frames)")
+	fs.StringVar(&c.AssetsDir, "assets", c.AssetsDir, "load font.png, teamg1_logo.png, gameone_logo.png, texture.png and icon.png from this directory when present")
+	fs.BoolVar(&c.Bloom, "bloom", c.Bloom, "glow around the bright areas of the main demo (toggle with G)")
+	fs.Float64Var(&c.PhosphorMask, "mask", c.PhosphorMask, "strength of the CRT phosphor mask over the picture, from 0 (off) to 1")
+	fs.Float64Var(&c.Brightness, "brightness", c.Brightness, "brightness of the final image, from 0 to 2 (1 leaves it unchanged)")
+	fs.Float64Var(&c.Gamma, "gamma", c.Gamma, "gamma correction of the final image, above 1 to lift dark areas (1 leaves it unchanged)")
+	fs.StringVar(&c.ShaderPath, "shader", c.ShaderPath, "load the CRT shader from a Kage file and reload it when it changes")
+}
+
+// validate replaces unusable settings with working ones, logging each change
+func (c *Config) validate() {
+	defaults := DefaultConfig()
+
+	c.SampleRate = validateSampleRate(c.SampleRate)
+
+	if c.WindowWidth <= 0 || c.WindowHeight <= 0 {
+		log.Printf("Invalid window size %dx%d, using %dx%d", c.WindowWidth, c.WindowHeight, defaults.WindowWidth, defaults.WindowHeight)
+		c.WindowWidth, c.WindowHeight = defaults.WindowWidth, defaults.WindowHeight
+	}
+
+	if c.Volume < 0 || c.Volume > 1 {
+		log.Printf("Volume %.2f out of range, clamping to [0, 1]", c.Volume)
+		c.Volume = math.Max(0, math.Min(1, c.Volume))
+	}
+
+	if c.StartScene != sceneIntro && c.StartScene != sceneDemo {
+		log.Printf("Unknown scene %q, starting with the %s", c.StartScene, defaults.StartScene)
+		c.StartScene = defaults.StartScene
+	}
+
+	if c.LogoCount < 1 {
+		log.Printf("Invalid logo count %d, using 1", c.LogoCount)
+		c.LogoCount = 1
+	}
+
+	if _, ok := transitionModes[c.Transition]; !ok {
+		log.Printf("Unknown transition %q, using %s", c.Transition, defaults.Transition)
+		c.Transition = defaults.Transition
+	}
+	if c.TransitionFrames < 0 {
+		c.TransitionFrames = 0
+	}
+
+	if c.IntroScrollSpeed < 1 {
+		log.Printf("Invalid intro scroll speed %d, using %d", c.IntroScrollSpeed, defaults.IntroScrollSpeed)
+		c.IntroScrollSpeed = defaults.IntroScrollSpeed
+	}
+	if c.IntroTickerSpeed < 1 {
+		log.Printf("Invalid intro ticker speed %d, using %d", c.IntroTickerSpeed, defaults.IntroTickerSpeed)
+		c.IntroTickerSpeed = defaults.IntroTickerSpeed
+	}
+	if maxY := screenHeight - int(fontHeight*tickerFontScale); c.IntroTickerY < 0 || c.IntroTickerY > maxY {
+		log.Printf("Intro ticker position %d off screen, using %d", c.IntroTickerY, defaults.IntroTickerY)
+		c.IntroTickerY = defaults.IntroTickerY
+	}
+
+	if _, ok := scrollModes[c.ScrollMode]; !ok {
+		log.Printf("Unknown scroll mode %q, using %s", c.ScrollMode, defaults.ScrollMode)
+		c.ScrollMode = defaults.ScrollMode
+	}
+	if c.TypewriterSpeed <= 0 {
+		log.Printf("Invalid typewriter speed %.2f, using %.2f", c.TypewriterSpeed, defaults.TypewriterSpeed)
+		c.TypewriterSpeed = defaults.TypewriterSpeed
+	}
+
+	if waveLength(c.ScrollWave) == 0 {
+		log.Printf("Empty scroll wave, using the default wave")
+		c.ScrollWave = defaults.ScrollWave
+	}
+
+	if _, ok := backgrounds[c.Background]; !ok {
+		log.Printf("Unknown background %q, using %s", c.Background, defaults.Background)
+		c.Background = defaults.Background
+	}
+	if c.StarCount < 1 {
+		log.Printf("Invalid star count %d, using 1", c.StarCount)
+		c.StarCount = 1
+	}
+	if c.FireIntensity < 0 || c.FireIntensity > 1 {
+		log.Printf("Fire intensity %.2f out of range, clamping to [0, 1]", c.FireIntensity)
+		c.FireIntensity = math.Max(0, math.Min(1, c.FireIntensity))
+	}
+	if c.FireCooling < 0 {
+		log.Printf("Invalid fire cooling %.2f, using %.2f", c.FireCooling, defaults.FireCooling)
+		c.FireCooling = defaults.FireCooling
+	}
+	if c.StarSpeed < 0 {
+		log.Printf("Invalid star speed %.2f, using %.2f", c.StarSpeed, defaults.StarSpeed)
+		c.StarSpeed = defaults.StarSpeed
+	}
+
+	if c.CopperCount < 1 {
+		log.Printf("Invalid copper bar count %d, using 1", c.CopperCount)
+		c.CopperCount = 1
+	}
+	if len(c.CopperColors) == 0 {
+		log.Printf("No copper bar colors, using the default colors")
+		c.CopperColors = defaults.CopperColors
+	}
+
+	if c.TwisterHeight < 1 || c.TwisterHeight > stCanvasHeight {
+		log.Printf("Twister height %d out of range, clamping to [1, %d]", c.TwisterHeight, stCanvasHeight)
+		c.TwisterHeight = max(1, min(stCanvasHeight, c.TwisterHeight))
+	}
+
+	if c.PhosphorMask < 0 || c.PhosphorMask > 1 {
+		log.Printf("Phosphor mask strength %.2f out of range, clamping to [0, 1]", c.PhosphorMask)
+		c.PhosphorMask = math.Max(0, math.Min(1, c.PhosphorMask))
+	}
+	if c.Brightness < 0 || c.Brightness > 2 {
+		log.Printf("Brightness %.2f out of range, clamping to [0, 2]", c.Brightness)
+		c.Brightness = math.Max(0, math.Min(2, c.Brightness))
+	}
+	if c.Gamma <= 0 {
+		log.Printf("Invalid gamma %.2f, using %.2f", c.Gamma, defaults.Gamma)
+		c.Gamma = defaults.Gamma
+	}
+
+	if c.StereoWidth < 0 || c.StereoWidth > 1 {
+		log.Printf("Stereo width %.2f out of range, clamping to [0, 1]", c.StereoWidth)
+		c.StereoWidth = math.Max(0, math.Min(1, c.StereoWidth))
+	}
+
+	if nyquist := float64(c.SampleRate) / 2; c.LowPassCutoff <= 0 || c.LowPassCutoff >= nyquist {
+		log.Printf("Low-pass cutoff %.0f Hz out of range, using %.0f Hz", c.LowPassCutoff, math.Min(defaults.LowPassCutoff, nyquist/2))
+		c.LowPassCutoff = math.Min(defaults.LowPassCutoff, nyquist/2)
+	}
+
+	for name := range c.Keys {
+		if _, ok := actionNames[name]; !ok {
+			log.Printf("Unknown action %q in the key bindings, ignoring it", name)
+			delete(c.Keys, name)
+		}
+	}
+
+	c.LogoAmplitude = math.Max(0, math.Min(logoAmplitudeMax, c.LogoAmplitude))
+	c.LogoSpeed = math.Max(0, math.Min(logoSpeedMax, c.LogoSpeed))
+
+	if c.ReflectionOpacity < 0 || c.ReflectionOpacity > 1 {
+		log.Printf("Reflection opacity %.2f out of range, clamping to [0, 1]", c.ReflectionOpacity)
+		c.ReflectionOpacity = math.Max(0, math.Min(1, c.ReflectionOpacity))
+	}
+	if c.ReflectionHeight < 0 {
+		log.Printf("Invalid reflection height %d, hiding the reflection", c.ReflectionHeight)
+		c.ReflectionHeight = 0
+	}
+
+	if c.ShakeMagnitude < 0 {
+		log.Printf("Invalid shake magnitude %.1f, disabling the shake", c.ShakeMagnitude)
+		c.ShakeMagnitude = 0
+	}
+	if c.ShakeDecay < 0 || c.ShakeDecay >= 1 {
+		log.Printf("Shake decay %.2f out of range, using %.2f", c.ShakeDecay, defaults.ShakeDecay)
+		c.ShakeDecay = defaults.ShakeDecay
+	}
+
+	if c.AttractDuration < 0 {
+		log.Printf("Invalid attract duration %.1f, waiting for the end of the tune", c.AttractDuration)
+		c.AttractDuration = 0
+	}
+}
+
+// Action is something the user triggers with a key
+type Action int
+
+const (
+	ActionFullscreen Action = iota
+	ActionVolumeUp
+	ActionVolumeDown
+	ActionPalette
+	ActionBackground
+	ActionCopperBars
+	ActionTwister
+	ActionPaletteCycling
+	ActionPerspective
+	ActionZBuffer
+	ActionLogoAmplitudeDown
+	ActionLogoAmplitudeUp
+	ActionLogoSpeedDown
+	ActionLogoSpeedUp
+	ActionIntroCRT
+	ActionDemoCRT
+	ActionCRTParam
+	ActionCRTParamUp
+	ActionCRTParamDown
+	ActionBloom
+	ActionScrollDirection
+	ActionScrollMode
+	ActionRainbow
+	ActionLogoHue
+	ActionVerticalRipple
+	ActionEnvMap
+	ActionSolid
+	ActionCubeGrid
+	ActionZoomIn
+	ActionZoomOut
+	ActionFOVModifier
+	ActionAutoRotate
+	ActionScreenshot
+	ActionDebug
+	ActionSaveConfig
+	ActionRestart
+	ActionPause
+	ActionSkipIntro
+	ActionQuit
+	ActionProgress
+	ActionLowPass
+	ActionMute
+	ActionScrollFringe
+	ActionMusicPause
+	ActionSeekBack
+	ActionSeekForward
+)
+
+// actionNames maps the configuration names of the actions
+var actionNames = map[string]Action{
+	"fullscreen":          ActionFullscreen,
+	"volume_up":           ActionVolumeUp,
+	"volume_down":         ActionVolumeDown,
+	"palette":             ActionPalette,
+	"background":          ActionBackground,
+	"copper_bars":         ActionCopperBars,
+	"twister":             ActionTwister,
+	"palette_cycling":     ActionPaletteCycling,
+	"perspective":         ActionPerspective,
+	"zbuffer":             ActionZBuffer,
+	"logo_amplitude_down": ActionLogoAmplitudeDown,
+	"logo_amplitude_up":   ActionLogoAmplitudeUp,
+	"logo_speed_down":     ActionLogoSpeedDown,
+	"logo_speed_up":       ActionLogoSpeedUp,
+	"intro_crt":           ActionIntroCRT,
+	"demo_crt":            ActionDemoCRT,
+	"crt_param":           ActionCRTParam,
+	"crt_param_up":        ActionCRTParamUp,
+	"crt_param_down":      ActionCRTParamDown,
+	"bloom":               ActionBloom,
+	"scroll_direction":    ActionScrollDirection,
+	"scroll_mode":         ActionScrollMode,
+	"rainbow":             ActionRainbow,
+	"logo_hue":            ActionLogoHue,
+	"vertical_ripple":     ActionVerticalRipple,
+	"env_map":             ActionEnvMap,
+	"solid":               ActionSolid,
+	"cube_grid":           ActionCubeGrid,
+	"zoom_in":             ActionZoomIn,
+	"zoom_out":            ActionZoomOut,
+	"fov_modifier":        ActionFOVModifier,
+	"auto_rotate":         ActionAutoRotate,
+	"screenshot":          ActionScreenshot,
+	"debug":               ActionDebug,
+	"save_config":         ActionSaveConfig,
+	"restart":             ActionRestart,
+	"pause":               ActionPause,
+	"skip_intro":          ActionSkipIntro,
+	"quit":                ActionQuit,
+	"progress":            ActionProgress,
+	"low_pass":            ActionLowPass,
+	"mute":                ActionMute,
+	"scroll_fringe":       ActionScrollFringe,
+	"music_pause":         ActionMusicPause,
+	"seek_back":           ActionSeekBack,
+	"seek_forward":        ActionSeekForward,
+}
+
+// defaultKeys returns the built-in key of every action, by action name
+func defaultKeys() map[string]ebiten.Key {
+	return map[string]ebiten.Key{
+		"fullscreen":          ebiten.KeyF,
+		"volume_up":           ebiten.KeyArrowUp,
+		"volume_down":         ebiten.KeyArrowDown,
+		"palette":             ebiten.KeyP,
+		"background":          ebiten.KeyB,
+		"copper_bars":         ebiten.KeyK,
+		"twister":             ebiten.KeyI,
+		"palette_cycling":     ebiten.KeyO,
+		"perspective":         ebiten.KeyDigit1,
+		"zbuffer":             ebiten.KeyDigit2,
+		"logo_amplitude_down": ebiten.KeyBracketLeft,
+		"logo_amplitude_up":   ebiten.KeyBracketRight,
+		"logo_speed_down":     ebiten.KeyComma,
+		"logo_speed_up":       ebiten.KeyPeriod,
+		"intro_crt":           ebiten.KeyC,
+		"demo_crt":            ebiten.KeyD,
+		"crt_param":           ebiten.KeyTab,
+		"crt_param_up":        ebiten.KeyPageUp,
+		"crt_param_down":      ebiten.KeyPageDown,
+		"bloom":               ebiten.KeyG,
+		"scroll_direction":    ebiten.KeyX,
+		"scroll_mode":         ebiten.KeyT,
+		"rainbow":             ebiten.KeyW,
+		"logo_hue":            ebiten.KeyH,
+		"vertical_ripple":     ebiten.KeyV,
+		"env_map":             ebiten.KeyE,
+		"solid":               ebiten.KeyN,
+		"cube_grid":           ebiten.KeyDigit3,
+		"zoom_in":             ebiten.KeyEqual,
+		"zoom_out":            ebiten.KeyMinus,
+		"fov_modifier":        ebiten.KeyShift,
+		"auto_rotate":         ebiten.KeyA,
+		"screenshot":          ebiten.KeyS,
+		"debug":               ebiten.KeyF3,
+		"save_config":         ebiten.KeyF2,
+		"restart":             ebiten.KeyR,
+		"pause":               ebiten.KeySpace,
+		"skip_intro":          ebiten.KeyEnter,
+		"quit":                ebiten.KeyEscape,
+		"progress":            ebiten.KeyF4,
+		"low_pass":            ebiten.KeyL,
+		"mute":                ebiten.KeyM,
+		"scroll_fringe":       ebiten.KeyJ,
+		"music_pause":         ebiten.KeyU,
+		"seek_back":           ebiten.KeyArrowLeft,
+		"seek_forward":        ebiten.KeyArrowRight,
+	}
+}
+
+// KeyBindings maps each action to its key
+type KeyBindings map[Action]ebiten.Key
+
+// newKeyBindings resolves the configured action names. Actions missing from keys get
+// their default key.
+func newKeyBindings(keys map[string]ebiten.Key) KeyBindings {
+	bindings := KeyBindings{}
+	for name, key := range defaultKeys() {
+		bindings[actionNames[name]] = key
+	}
+	for name, key := range keys {
+		if action, ok := actionNames[name]; ok {
+			bindings[action] = key
+		}
+	}
+	return bindings
+}
+
+// saveConfig writes the settings, including the live logo distortion tuning, to configPath
+func (g *Game) saveConfig() {
+	cfg := g.cfg
+	cfg.LogoAmplitude = g.logoDistort.amplitude
+	cfg.LogoSpeed = g.logoDistort.speed
+	cfg.ScrollReverse = g.scrollReverse
+	cfg.ScrollFringe = g.scrollFringe
+	cfg.Volume = g.volume
+	cfg.CopperBars = g.copperBars
+	cfg.Twister = g.twister
+	cfg.LowPass = g.lowPass
+	cfg.Bloom = g.bloomEnabled
+	for name, background := range backgrounds {
+		if background == g.background {
+			cfg.Background = name
+		}
+	}
+	for name, mode := range scrollModes {
+		if mode == g.scrollMode {
+			cfg.ScrollMode = name
+		}
+	}
+
+	if err := cfg.Save(configPath); err != nil {
+		log.Printf("Failed to save settings: %v", err)
+		return
+	}
+	log.Printf("Settings saved to %s", configPath)
+}
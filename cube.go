@@ -0,0 +1,902 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	// Grid size used to subdivide cube faces for perspective-correct texturing
+	cubeSubdivisions = 8
+
+	// Minimum brightness of cube faces turned away from the light
+	cubeAmbient = 0.3
+
+	// Mouse control of the cube
+	cubeDragSpeed = 0.01 // Radians per pixel dragged
+	cubeFriction  = 0.97 // Share of the spin kept each step after release
+
+	// Cube projection parameters
+	defaultCubeFOV      = 300.0 // Focal length in pixels
+	defaultCubeDistance = 300.0 // Distance from the focal plane to the cube center
+	cubeFOVMin          = 100.0
+	cubeFOVMax          = 1200.0
+	cubeDistanceMin     = 0.0
+	cubeDistanceMax     = 2000.0
+	cubeZoomStep        = 5.0 // Per-frame change while a zoom key is held
+	cubeNearDepth       = 1.0 // Smallest depth used for projection, avoids division by zero
+	cubeMaxScale        = 8.0 // Upper bound of the perspective scale factor
+)
+
+// cubeLightDirection points from the cube towards the light (upper left, in front)
+var cubeLightDirection = Vector3{X: -0.4, Y: -0.5, Z: -1}
+
+// Vector3 represents a 3D point in space
+type Vector3 struct {
+	X, Y, Z float64
+}
+
+// Face represents a textured quad face.
+// Triangles are stored as degenerate quads with P4 == P3 and UV4 == UV3.
+type Face struct {
+	P1, P2, P3, P4     int
+	UV1, UV2, UV3, UV4 [2]float32 // Texture coordinates
+	TextureID          int        // Index into Game.textures, 0 being the default texture
+}
+
+// initCube initializes the 3D textured cube from the embedded OBJ mesh
+func (g *Game) initCube() {
+	size := 100.0
+
+	vertices, faces, err := parseOBJ(cubeOBJData)
+	if err == nil {
+		for i := range vertices {
+			vertices[i].X *= size
+			vertices[i].Y *= size
+			vertices[i].Z *= size
+		}
+		g.cubeVertices = vertices
+		g.cubeFaces = faces
+		return
+	}
+	log.Printf("Failed to load cube mesh, using built-in cube: %v", err)
+
+	// Cube vertices
+	g.cubeVertices = []Vector3{
+		{-size, -size, -size}, // 0
+		{size, -size, -size},  // 1
+		{size, size, -size},   // 2
+		{-size, size, -size},  // 3
+		{-size, -size, size},  // 4
+		{size, -size, size},   // 5
+		{size, size, size},    // 6
+		{-size, size, size},   // 7
+	}
+
+	// Cube faces with texture coordinates
+	g.cubeFaces = []Face{
+		{4, 5, 6, 7, [2]float32{1, 0}, [2]float32{0, 0}, [2]float32{0, 1}, [2]float32{1, 1}, 0}, // Front
+		{1, 0, 3, 2, [2]float32{1, 0}, [2]float32{0, 0}, [2]float32{0, 1}, [2]float32{1, 1}, 0}, // Back
+		{5, 1, 2, 6, [2]float32{1, 0}, [2]float32{0, 0}, [2]float32{0, 1}, [2]float32{1, 1}, 0}, // Right
+		{0, 4, 7, 3, [2]float32{1, 0}, [2]float32{0, 0}, [2]float32{0, 1}, [2]float32{1, 1}, 0}, // Left
+		{7, 6, 2, 3, [2]float32{1, 0}, [2]float32{0, 0}, [2]float32{0, 1}, [2]float32{1, 1}, 0}, // Top
+		{0, 1, 5, 4, [2]float32{1, 0}, [2]float32{0, 0}, [2]float32{0, 1}, [2]float32{1, 1}, 0}, // Bottom
+	}
+}
+
+// Solid selects the mesh drawn by drawTexturedCube
+type Solid int
+
+const (
+	SolidCube Solid = iota
+	SolidTetrahedron
+	SolidOctahedron
+	SolidIcosahedron
+	solidCount
+)
+
+// envMapTexture is the index in Game.textures of the spherical environment map
+const envMapTexture = 0
+
+// solidRadius is the circumradius of the generated solids, matching the cube's
+var solidRadius = 100 * math.Sqrt(3)
+
+// setSolid replaces the cube mesh with the selected solid
+func (g *Game) setSolid(s Solid) {
+	g.solid = s
+
+	var vertices []Vector3
+	var faces []Face
+	switch s {
+	case SolidTetrahedron:
+		vertices, faces = newTetrahedron()
+	case SolidOctahedron:
+		vertices, faces = newOctahedron()
+	case SolidIcosahedron:
+		vertices, faces = newIcosahedron()
+	default:
+		g.initCube()
+		return
+	}
+
+	for i := range vertices {
+		vertices[i].X *= solidRadius
+		vertices[i].Y *= solidRadius
+		vertices[i].Z *= solidRadius
+	}
+	g.cubeVertices = vertices
+	g.cubeFaces = faces
+}
+
+// newTetrahedron returns a tetrahedron with unit circumradius
+func newTetrahedron() ([]Vector3, []Face) {
+	return newRegularSolid([]Vector3{
+		{1, 1, 1}, {1, -1, -1}, {-1, 1, -1}, {-1, -1, 1},
+	})
+}
+
+// newOctahedron returns an octahedron with unit circumradius
+func newOctahedron() ([]Vector3, []Face) {
+	return newRegularSolid([]Vector3{
+		{1, 0, 0}, {-1, 0, 0}, {0, 1, 0}, {0, -1, 0}, {0, 0, 1}, {0, 0, -1},
+	})
+}
+
+// newIcosahedron returns an icosahedron with unit circumradius
+func newIcosahedron() ([]Vector3, []Face) {
+	phi := (1 + math.Sqrt(5)) / 2
+	var vertices []Vector3
+	for _, a := range []float64{-1, 1} {
+		for _, b := range []float64{-phi, phi} {
+			vertices = append(vertices,
+				Vector3{0, a, b},
+				Vector3{a, b, 0},
+				Vector3{b, 0, a},
+			)
+		}
+	}
+	return newRegularSolid(vertices)
+}
+
+// newRegularSolid normalizes the vertices of a triangle-faced platonic solid
+// and builds its faces from every triple of vertices at edge length from each other.
+// Faces are wound like the cube's, with (P2-P1)×(P3-P1) pointing outward.
+func newRegularSolid(vertices []Vector3) ([]Vector3, []Face) {
+	for i := range vertices {
+		vertices[i] = vertices[i].normalize()
+	}
+
+	// The edge length is the shortest distance between two vertices
+	edge := math.Inf(1)
+	for i := range vertices {
+		for j := i + 1; j < len(vertices); j++ {
+			d := vertices[j].sub(vertices[i])
+			edge = math.Min(edge, math.Sqrt(d.dot(d)))
+		}
+	}
+	isEdge := func(a, b int) bool {
+		d := vertices[b].sub(vertices[a])
+		return math.Abs(math.Sqrt(d.dot(d))-edge) < 1e-6
+	}
+
+	var faces []Face
+	for i := range vertices {
+		for j := i + 1; j < len(vertices); j++ {
+			if !isEdge(i, j) {
+				continue
+			}
+			for k := j + 1; k < len(vertices); k++ {
+				if !isEdge(i, k) || !isEdge(j, k) {
+					continue
+				}
+
+				// Flip the winding when the normal points towards the center
+				p2, p3 := j, k
+				normal := vertices[j].sub(vertices[i]).cross(vertices[k].sub(vertices[i]))
+				if normal.dot(vertices[i]) < 0 {
+					p2, p3 = k, j
+				}
+
+				faces = append(faces, Face{
+					P1: i, P2: p2, P3: p3, P4: p3,
+					UV1: [2]float32{0.5, 0}, UV2: [2]float32{1, 1}, UV3: [2]float32{0, 1}, UV4: [2]float32{0, 1},
+				})
+			}
+		}
+	}
+	return vertices, faces
+}
+
+// objMaterials maps OBJ material names to texture IDs in Game.textures
+var objMaterials = map[string]int{
+	"texture": 0,
+	"teamg1":  1,
+	"gameone": 2,
+}
+
+// parseOBJ reads vertices, texture coordinates, materials and faces from Wavefront OBJ data.
+// Quads are kept as is, triangles become degenerate quads and larger polygons are fanned.
+func parseOBJ(data []byte) ([]Vector3, []Face, error) {
+	var vertices []Vector3
+	var uvs [][2]float32
+	var faces []Face
+
+	// Corner UVs used when a face has no texture coordinates
+	defaultUVs := [4][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+
+	// Texture selected by the last usemtl statement
+	textureID := 0
+
+	for lineNum, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		switch fields[0] {
+		case "v":
+			if len(fields) < 4 {
+				return nil, nil, fmt.Errorf("line %d: vertex needs 3 coordinates", lineNum+1)
+			}
+			var coords [3]float64
+			for i := range coords {
+				value, err := strconv.ParseFloat(fields[i+1], 64)
+				if err != nil {
+					return nil, nil, fmt.Errorf("line %d: %w", lineNum+1, err)
+				}
+				coords[i] = value
+			}
+			vertices = append(vertices, Vector3{X: coords[0], Y: coords[1], Z: coords[2]})
+
+		case "vt":
+			if len(fields) < 3 {
+				return nil, nil, fmt.Errorf("line %d: texture coordinate needs 2 values", lineNum+1)
+			}
+			u, err := strconv.ParseFloat(fields[1], 32)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: %w", lineNum+1, err)
+			}
+			v, err := strconv.ParseFloat(fields[2], 32)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: %w", lineNum+1, err)
+			}
+			// OBJ texture space has V pointing up, image space has Y pointing down
+			uvs = append(uvs, [2]float32{float32(u), float32(1 - v)})
+
+		case "usemtl":
+			// Unknown materials use the default texture
+			textureID = 0
+			if len(fields) > 1 {
+				textureID = objMaterials[fields[1]]
+			}
+
+		case "f":
+			if len(fields) < 4 {
+				return nil, nil, fmt.Errorf("line %d: face needs at least 3 vertices", lineNum+1)
+			}
+			points := make([]int, 0, len(fields)-1)
+			corners := make([][2]float32, 0, len(fields)-1)
+			for i, field := range fields[1:] {
+				refs := strings.Split(field, "/")
+				p, err := objIndex(refs[0], len(vertices))
+				if err != nil {
+					return nil, nil, fmt.Errorf("line %d: %w", lineNum+1, err)
+				}
+				uv := defaultUVs[i%4]
+				if len(refs) > 1 && refs[1] != "" {
+					t, err := objIndex(refs[1], len(uvs))
+					if err != nil {
+						return nil, nil, fmt.Errorf("line %d: %w", lineNum+1, err)
+					}
+					uv = uvs[t]
+				}
+				points = append(points, p)
+				corners = append(corners, uv)
+			}
+
+			if len(points) == 4 {
+				faces = append(faces, Face{points[0], points[1], points[2], points[3], corners[0], corners[1], corners[2], corners[3], textureID})
+				continue
+			}
+			for i := 1; i+1 < len(points); i++ {
+				faces = append(faces, Face{points[0], points[i], points[i+1], points[i+1], corners[0], corners[i], corners[i+1], corners[i+1], textureID})
+			}
+		}
+	}
+
+	if len(vertices) == 0 || len(faces) == 0 {
+		return nil, nil, fmt.Errorf("mesh has no vertices or faces")
+	}
+	return vertices, faces, nil
+}
+
+// objIndex converts a 1-based (or negative, relative) OBJ index into a slice index
+func objIndex(ref string, count int) (int, error) {
+	index, err := strconv.Atoi(ref)
+	if err != nil {
+		return 0, err
+	}
+	if index < 0 {
+		index += count
+	} else {
+		index--
+	}
+	if index < 0 || index >= count {
+		return 0, fmt.Errorf("index %s out of range", ref)
+	}
+	return index, nil
+}
+
+// drawTexturedCube draws the 3D textured cube
+func (g *Game) drawTexturedCube() {
+	g.cubeCanvas.Clear()
+
+	// Transform the vertices of every cube instance into one shared list,
+	// with a rotation matrix computed once per instance and frame
+	camera := Vector3{Z: -(g.cubeFOV + g.cubeDistance)}
+	transformedVertices := make([]Vector3, 0, len(g.cubes)*len(g.cubeVertices))
+	allFaces := make([]Face, 0, len(g.cubes)*len(g.cubeFaces))
+	var normals []Vector3
+	for _, cube := range g.cubes {
+		base := len(transformedVertices)
+		rotation := rotationMatrix(Vector3{
+			X: g.cubeRotation.X*cube.Speed + cube.Phase.X,
+			Y: g.cubeRotation.Y*cube.Speed + cube.Phase.Y,
+			Z: g.cubeRotation.Z*cube.Speed + cube.Phase.Z,
+		})
+		for _, v := range g.cubeVertices {
+			v = rotation.apply(Vector3{X: v.X * cube.Scale, Y: v.Y * cube.Scale, Z: v.Z * cube.Scale})
+			transformedVertices = append(transformedVertices, Vector3{
+				X: v.X + cube.Position.X,
+				Y: v.Y + cube.Position.Y,
+				Z: v.Z + cube.Position.Z,
+			})
+		}
+
+		// Reflection UVs for chrome instances, from smooth vertex normals
+		var envUVs [][2]float32
+		if cube.EnvMapped {
+			if normals == nil {
+				normals = vertexNormals(g.cubeVertices, g.cubeFaces)
+			}
+			envUVs = make([][2]float32, len(normals))
+			for i, n := range normals {
+				envUVs[i] = sphereMapUV(transformedVertices[base+i], rotation.apply(n), camera)
+			}
+		}
+
+		for _, face := range g.cubeFaces {
+			if envUVs != nil {
+				face.UV1, face.UV2, face.UV3, face.UV4 = envUVs[face.P1], envUVs[face.P2], envUVs[face.P3], envUVs[face.P4]
+				face.TextureID = envMapTexture
+			}
+			face.P1 += base
+			face.P2 += base
+			face.P3 += base
+			face.P4 += base
+			allFaces = append(allFaces, face)
+		}
+	}
+
+	// Sort faces of all instances by depth together so cubes intersect correctly
+	type faceDepth struct {
+		face  Face
+		depth float64
+	}
+
+	faces := make([]faceDepth, len(allFaces))
+	for i, face := range allFaces {
+		var avgZ float64
+		if face.P4 == face.P3 {
+			avgZ = (transformedVertices[face.P1].Z + transformedVertices[face.P2].Z +
+				transformedVertices[face.P3].Z) / 3.0
+		} else {
+			avgZ = (transformedVertices[face.P1].Z + transformedVertices[face.P2].Z +
+				transformedVertices[face.P3].Z + transformedVertices[face.P4].Z) / 4.0
+		}
+		faces[i] = faceDepth{face: face, depth: avgZ}
+	}
+
+	// Far to near, so nearer faces are painted over farther ones
+	sort.Slice(faces, func(i, j int) bool {
+		return faces[i].depth > faces[j].depth
+	})
+
+	if g.zBuffer {
+		g.cubeRaster.clear()
+	}
+
+	// Draw faces
+	centerX := float32(g.cubeCanvas.Bounds().Dx() / 2)
+	centerY := float32(g.cubeCanvas.Bounds().Dy() / 2)
+	light := cubeLightDirection.normalize()
+
+	for _, fd := range faces {
+		face := fd.face
+
+		// Backface culling
+		p1, p2, p3 := transformedVertices[face.P1], transformedVertices[face.P2], transformedVertices[face.P3]
+		if !faceVisible(p1, p2, p3, camera) {
+			continue
+		}
+		normal := p2.sub(p1).cross(p3.sub(p1))
+
+		// Texture for this face, falling back to the default one
+		tex := g.textures[0]
+		if face.TextureID > 0 && face.TextureID < len(g.textures) {
+			tex = g.textures[face.TextureID]
+		}
+
+		// Flat shading from the outward normal
+		shade := float32(cubeAmbient + (1-cubeAmbient)*math.Max(0, normal.normalize().dot(light)))
+
+		// Project vertices
+		var screenPoints [4][2]float32
+		for i, p := range []int{face.P1, face.P2, face.P3, face.P4} {
+			screenPoints[i][0], screenPoints[i][1] = projectPoint(transformedVertices[p], g.cubeFOV, g.cubeDistance, centerX, centerY)
+		}
+
+		// Software rasterization with depth testing
+		if g.zBuffer {
+			var corners [4]rasterVertex
+			uvs := [4][2]float32{face.UV1, face.UV2, face.UV3, face.UV4}
+			for i, p := range []int{face.P1, face.P2, face.P3, face.P4} {
+				corners[i] = rasterVertex{
+					x:    float64(screenPoints[i][0]),
+					y:    float64(screenPoints[i][1]),
+					invZ: 1 / projectionDepth(transformedVertices[p].Z, g.cubeFOV, g.cubeDistance),
+					u:    float64(uvs[i][0]),
+					v:    float64(uvs[i][1]),
+				}
+			}
+			rasterTex := g.cubeRaster.textureFor(tex)
+			g.cubeRaster.drawTriangle(corners[0], corners[1], corners[2], rasterTex, float64(shade))
+			if face.P4 != face.P3 {
+				g.cubeRaster.drawTriangle(corners[0], corners[2], corners[3], rasterTex, float64(shade))
+			}
+			continue
+		}
+
+		// Draw textured quad
+		vertices := []ebiten.Vertex{
+			{
+				DstX: screenPoints[0][0], DstY: screenPoints[0][1],
+				SrcX:   face.UV1[0] * float32(tex.Bounds().Dx()),
+				SrcY:   face.UV1[1] * float32(tex.Bounds().Dy()),
+				ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1,
+			},
+			{
+				DstX: screenPoints[1][0], DstY: screenPoints[1][1],
+				SrcX:   face.UV2[0] * float32(tex.Bounds().Dx()),
+				SrcY:   face.UV2[1] * float32(tex.Bounds().Dy()),
+				ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1,
+			},
+			{
+				DstX: screenPoints[2][0], DstY: screenPoints[2][1],
+				SrcX:   face.UV3[0] * float32(tex.Bounds().Dx()),
+				SrcY:   face.UV3[1] * float32(tex.Bounds().Dy()),
+				ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1,
+			},
+			{
+				DstX: screenPoints[3][0], DstY: screenPoints[3][1],
+				SrcX:   face.UV4[0] * float32(tex.Bounds().Dx()),
+				SrcY:   face.UV4[1] * float32(tex.Bounds().Dy()),
+				ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1,
+			},
+		}
+
+		indices := []uint16{0, 1, 2, 0, 2, 3}
+
+		// Replace the affine quad with a grid of small projected quads
+		if g.perspectiveCorrect {
+			corners := [4]Vector3{
+				transformedVertices[face.P1], transformedVertices[face.P2],
+				transformedVertices[face.P3], transformedVertices[face.P4],
+			}
+			uvs := [4][2]float32{face.UV1, face.UV2, face.UV3, face.UV4}
+			vertices, indices = subdivideFace(corners, uvs, cubeSubdivisions, tex.Bounds(), func(v Vector3) (float32, float32) {
+				return projectPoint(v, g.cubeFOV, g.cubeDistance, centerX, centerY)
+			})
+		}
+
+		for i := range vertices {
+			vertices[i].ColorR = shade
+			vertices[i].ColorG = shade
+			vertices[i].ColorB = shade
+		}
+
+		op := &ebiten.DrawTrianglesOptions{}
+		g.cubeCanvas.DrawTriangles(vertices, indices, tex, op)
+	}
+
+	if g.zBuffer {
+		g.cubeCanvas.WritePixels(g.cubeRaster.pixels)
+	}
+}
+
+// projectionDepth returns the distance of a cube-space z from the camera, fov+zOffset in front
+// of the origin, never closer than the near depth so vertices behind the camera stay finite
+func projectionDepth(z, fov, zOffset float64) float64 {
+	return math.Max(fov+zOffset+z, cubeNearDepth)
+}
+
+// projectPoint projects a transformed cube-space point to the screen, around the center cx, cy
+func projectPoint(v Vector3, fov, zOffset float64, cx, cy float32) (float32, float32) {
+	scale := math.Min(fov/projectionDepth(v.Z, fov, zOffset), cubeMaxScale)
+	return cx + float32(v.X*scale), cy + float32(v.Y*scale)
+}
+
+// faceVisible reports whether the face with the first three transformed vertices p1, p2, p3
+// faces the camera. Faces are wound with (P2-P1)×(P3-P1) pointing out of the mesh, so front
+// faces have that normal towards the camera, the same as a counter-clockwise projected
+// winding on the y-down screen.
+func faceVisible(p1, p2, p3, camera Vector3) bool {
+	normal := p2.sub(p1).cross(p3.sub(p1))
+	return normal.dot(p1.sub(camera)) < 0
+}
+
+// updateCubeDrag turns the cube while it is dragged with the left mouse button.
+// The last movement becomes the spin it keeps after release.
+func (g *Game) updateCubeDrag() {
+	x, y := ebiten.CursorPosition()
+
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		g.cubeManual = true
+		g.cubeDragging = true
+		g.dragX, g.dragY = x, y
+		g.cubeSpin = Vector3{}
+	}
+	if !g.cubeDragging {
+		return
+	}
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		g.cubeDragging = false
+		return
+	}
+
+	// Horizontal drags turn around the vertical axis, vertical drags around the horizontal one
+	g.cubeSpin = Vector3{
+		X: float64(y-g.dragY) * cubeDragSpeed,
+		Y: float64(x-g.dragX) * cubeDragSpeed,
+	}
+	g.cubeRotation = g.cubeRotation.add(g.cubeSpin)
+	g.dragX, g.dragY = x, y
+}
+
+// updateCubeZoom adjusts the camera distance, or the field of view with Shift held
+func (g *Game) updateCubeZoom() {
+	step := 0.0
+	if g.pressed(ActionZoomIn) {
+		step = -cubeZoomStep
+	}
+	if g.pressed(ActionZoomOut) {
+		step = cubeZoomStep
+	}
+	if step == 0 {
+		return
+	}
+
+	if g.pressed(ActionFOVModifier) {
+		// A longer focal length narrows the view, so zooming in raises it
+		g.cubeFOV = math.Max(cubeFOVMin, math.Min(cubeFOVMax, g.cubeFOV-step))
+	} else {
+		g.cubeDistance = math.Max(cubeDistanceMin, math.Min(cubeDistanceMax, g.cubeDistance+step))
+	}
+}
+
+// vertexNormals returns smooth per-vertex normals, averaged from the faces sharing each vertex
+func vertexNormals(vertices []Vector3, faces []Face) []Vector3 {
+	normals := make([]Vector3, len(vertices))
+	for _, face := range faces {
+		p1 := vertices[face.P1]
+		n := vertices[face.P2].sub(p1).cross(vertices[face.P3].sub(p1)).normalize()
+		points := []int{face.P1, face.P2, face.P3}
+		if face.P4 != face.P3 {
+			points = append(points, face.P4)
+		}
+		for _, p := range points {
+			normals[p] = Vector3{X: normals[p].X + n.X, Y: normals[p].Y + n.Y, Z: normals[p].Z + n.Z}
+		}
+	}
+	for i := range normals {
+		normals[i] = normals[i].normalize()
+	}
+	return normals
+}
+
+// sphereMapUV returns the spherical environment map coordinates seen reflected
+// at position with the given normal. The map center reflects straight back to the camera.
+func sphereMapUV(position, normal, camera Vector3) [2]float32 {
+	d := position.sub(camera).normalize()
+	dn := 2 * d.dot(normal)
+	r := Vector3{X: d.X - dn*normal.X, Y: d.Y - dn*normal.Y, Z: d.Z - dn*normal.Z}
+
+	m := 2 * math.Sqrt(r.X*r.X+r.Y*r.Y+(r.Z-1)*(r.Z-1))
+	if m == 0 {
+		return [2]float32{0.5, 0.5}
+	}
+	return [2]float32{float32(r.X/m + 0.5), float32(r.Y/m + 0.5)}
+}
+
+// CubeInstance places one copy of the cube mesh in the scene
+type CubeInstance struct {
+	Position  Vector3 // World offset from the canvas center
+	Phase     Vector3 // Rotation offset added to the shared cube rotation
+	Speed     float64 // Multiplier applied to the shared cube rotation
+	Scale     float64 // Mesh scale
+	EnvMapped bool    // Chrome look: reflection of the environment map instead of face textures
+}
+
+// singleCube returns the default layout: one full-size cube at the center
+func singleCube() []CubeInstance {
+	return []CubeInstance{{Speed: 1, Scale: 1}}
+}
+
+// cubeGridLayout returns n×n smaller cubes with varied rotation phases and speeds
+func cubeGridLayout(n int, spacing float64) []CubeInstance {
+	cubes := make([]CubeInstance, 0, n*n)
+	offset := float64(n-1) / 2
+	for row := 0; row < n; row++ {
+		for col := 0; col < n; col++ {
+			i := row*n + col
+			cubes = append(cubes, CubeInstance{
+				Position: Vector3{X: (float64(col) - offset) * spacing, Y: (float64(row) - offset) * spacing},
+				Phase:    Vector3{X: float64(i) * 0.7, Y: float64(i) * 0.5, Z: float64(i) * 0.3},
+				Speed:    0.8 + 0.4*float64(i%3)/2,
+				Scale:    0.4,
+			})
+		}
+	}
+	return cubes
+}
+
+// DepthRaster is a software triangle rasterizer with a per-pixel z-buffer.
+// It is slower than DrawTriangles but resolves overlaps correctly for any mesh.
+type DepthRaster struct {
+	width    int
+	height   int
+	depth    []float64 // 1/z per pixel, 0 means empty
+	pixels   []byte
+	textures map[*ebiten.Image]*rasterTexture
+}
+
+// rasterTexture is a CPU copy of a texture for the software rasterizer
+type rasterTexture struct {
+	width  int
+	height int
+	pixels []byte
+}
+
+// rasterVertex is a projected vertex with its inverse depth and texture coordinates
+type rasterVertex struct {
+	x, y float64
+	invZ float64
+	u, v float64
+}
+
+// NewDepthRaster creates a rasterizer for a canvas of the given size
+func NewDepthRaster(width, height int) *DepthRaster {
+	return &DepthRaster{
+		width:    width,
+		height:   height,
+		depth:    make([]float64, width*height),
+		pixels:   make([]byte, 4*width*height),
+		textures: make(map[*ebiten.Image]*rasterTexture),
+	}
+}
+
+// clear resets the color and depth buffers
+func (r *DepthRaster) clear() {
+	for i := range r.depth {
+		r.depth[i] = 0
+	}
+	for i := range r.pixels {
+		r.pixels[i] = 0
+	}
+}
+
+// textureFor returns the CPU copy of img, reading it back from the GPU the first time
+func (r *DepthRaster) textureFor(img *ebiten.Image) *rasterTexture {
+	if tex, ok := r.textures[img]; ok {
+		return tex
+	}
+
+	tex := &rasterTexture{
+		width:  img.Bounds().Dx(),
+		height: img.Bounds().Dy(),
+	}
+	tex.pixels = make([]byte, 4*tex.width*tex.height)
+	img.ReadPixels(tex.pixels)
+	r.textures[img] = tex
+	return tex
+}
+
+// drawTriangle rasterizes a textured, shaded triangle with depth testing and
+// perspective-correct texture coordinates
+func (r *DepthRaster) drawTriangle(a, b, c rasterVertex, tex *rasterTexture, shade float64) {
+	area := edgeFunction(a, b, c.x, c.y)
+	if area == 0 {
+		return
+	}
+
+	// Bounding box clipped to the canvas
+	minX := int(math.Max(0, math.Floor(math.Min(a.x, math.Min(b.x, c.x)))))
+	maxX := int(math.Min(float64(r.width-1), math.Ceil(math.Max(a.x, math.Max(b.x, c.x)))))
+	minY := int(math.Max(0, math.Floor(math.Min(a.y, math.Min(b.y, c.y)))))
+	maxY := int(math.Min(float64(r.height-1), math.Ceil(math.Max(a.y, math.Max(b.y, c.y)))))
+
+	for y := minY; y <= maxY; y++ {
+		py := float64(y) + 0.5
+		for x := minX; x <= maxX; x++ {
+			px := float64(x) + 0.5
+
+			// Barycentric weights, accepting both windings
+			w0 := edgeFunction(b, c, px, py) / area
+			w1 := edgeFunction(c, a, px, py) / area
+			w2 := edgeFunction(a, b, px, py) / area
+			if w0 < 0 || w1 < 0 || w2 < 0 {
+				continue
+			}
+
+			// Nearer pixels have a larger 1/z
+			invZ := w0*a.invZ + w1*b.invZ + w2*c.invZ
+			i := y*r.width + x
+			if invZ <= r.depth[i] {
+				continue
+			}
+			r.depth[i] = invZ
+
+			// Perspective-correct texture coordinates
+			u := (w0*a.u*a.invZ + w1*b.u*b.invZ + w2*c.u*c.invZ) / invZ
+			v := (w0*a.v*a.invZ + w1*b.v*b.invZ + w2*c.v*c.invZ) / invZ
+			tx := int(u * float64(tex.width))
+			ty := int(v * float64(tex.height))
+			tx = min(max(tx, 0), tex.width-1)
+			ty = min(max(ty, 0), tex.height-1)
+
+			t := (ty*tex.width + tx) * 4
+			r.pixels[i*4] = uint8(float64(tex.pixels[t]) * shade)
+			r.pixels[i*4+1] = uint8(float64(tex.pixels[t+1]) * shade)
+			r.pixels[i*4+2] = uint8(float64(tex.pixels[t+2]) * shade)
+			r.pixels[i*4+3] = tex.pixels[t+3]
+		}
+	}
+}
+
+// edgeFunction returns twice the signed area of the triangle (a, b, p)
+func edgeFunction(a, b rasterVertex, px, py float64) float64 {
+	return (b.x-a.x)*(py-a.y) - (b.y-a.y)*(px-a.x)
+}
+
+// subdivideFace splits a quad into an n×n grid, projecting every grid point so that
+// the texture only interpolates affinely across small cells, approximating perspective correction
+func subdivideFace(corners [4]Vector3, uvs [4][2]float32, n int, tex image.Rectangle, project func(Vector3) (float32, float32)) ([]ebiten.Vertex, []uint16) {
+	vertices := make([]ebiten.Vertex, 0, (n+1)*(n+1))
+	for j := 0; j <= n; j++ {
+		t := float64(j) / float64(n)
+		for i := 0; i <= n; i++ {
+			s := float64(i) / float64(n)
+
+			// Bilinear interpolation over the quad corners (P1, P2 along s; P4, P3 below)
+			pos := lerpVector3(lerpVector3(corners[0], corners[1], s), lerpVector3(corners[3], corners[2], s), t)
+			u := lerpFloat(lerpFloat(float64(uvs[0][0]), float64(uvs[1][0]), s), lerpFloat(float64(uvs[3][0]), float64(uvs[2][0]), s), t)
+			v := lerpFloat(lerpFloat(float64(uvs[0][1]), float64(uvs[1][1]), s), lerpFloat(float64(uvs[3][1]), float64(uvs[2][1]), s), t)
+
+			x, y := project(pos)
+			vertices = append(vertices, ebiten.Vertex{
+				DstX: x, DstY: y,
+				SrcX:   float32(u) * float32(tex.Dx()),
+				SrcY:   float32(v) * float32(tex.Dy()),
+				ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1,
+			})
+		}
+	}
+
+	indices := make([]uint16, 0, n*n*6)
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			a := uint16(j*(n+1) + i)
+			b := a + 1
+			c := a + uint16(n) + 2
+			d := a + uint16(n) + 1
+			indices = append(indices, a, b, c, a, c, d)
+		}
+	}
+	return vertices, indices
+}
+
+// Matrix3 is a 3×3 row-major matrix
+type Matrix3 [3][3]float64
+
+// rotationMatrix returns the matrix rotating around X, then Y, then Z by the given angles
+func rotationMatrix(rot Vector3) Matrix3 {
+	sx, cx := math.Sincos(rot.X)
+	sy, cy := math.Sincos(rot.Y)
+	sz, cz := math.Sincos(rot.Z)
+
+	rx := Matrix3{{1, 0, 0}, {0, cx, -sx}, {0, sx, cx}}
+	ry := Matrix3{{cy, 0, sy}, {0, 1, 0}, {-sy, 0, cy}}
+	rz := Matrix3{{cz, -sz, 0}, {sz, cz, 0}, {0, 0, 1}}
+
+	return rz.mul(ry).mul(rx)
+}
+
+// mul returns the matrix product m × o
+func (m Matrix3) mul(o Matrix3) Matrix3 {
+	var r Matrix3
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			r[i][j] = m[i][0]*o[0][j] + m[i][1]*o[1][j] + m[i][2]*o[2][j]
+		}
+	}
+	return r
+}
+
+// apply returns the vector transformed by m
+func (m Matrix3) apply(v Vector3) Vector3 {
+	return Vector3{
+		X: m[0][0]*v.X + m[0][1]*v.Y + m[0][2]*v.Z,
+		Y: m[1][0]*v.X + m[1][1]*v.Y + m[1][2]*v.Z,
+		Z: m[2][0]*v.X + m[2][1]*v.Y + m[2][2]*v.Z,
+	}
+}
+
+// add returns v + o
+func (v Vector3) add(o Vector3) Vector3 {
+	return Vector3{X: v.X + o.X, Y: v.Y + o.Y, Z: v.Z + o.Z}
+}
+
+// scale returns v multiplied by s
+func (v Vector3) scale(s float64) Vector3 {
+	return Vector3{X: v.X * s, Y: v.Y * s, Z: v.Z * s}
+}
+
+// sub returns v - o
+func (v Vector3) sub(o Vector3) Vector3 {
+	return Vector3{X: v.X - o.X, Y: v.Y - o.Y, Z: v.Z - o.Z}
+}
+
+// cross returns the cross product v × o
+func (v Vector3) cross(o Vector3) Vector3 {
+	return Vector3{
+		X: v.Y*o.Z - v.Z*o.Y,
+		Y: v.Z*o.X - v.X*o.Z,
+		Z: v.X*o.Y - v.Y*o.X,
+	}
+}
+
+// dot returns the dot product v · o
+func (v Vector3) dot(o Vector3) float64 {
+	return v.X*o.X + v.Y*o.Y + v.Z*o.Z
+}
+
+// normalize returns v scaled to unit length (or v itself if it has no length)
+func (v Vector3) normalize() Vector3 {
+	length := math.Sqrt(v.dot(v))
+	if length == 0 {
+		return v
+	}
+	return Vector3{X: v.X / length, Y: v.Y / length, Z: v.Z / length}
+}
+
+// lerpVector3 linearly interpolates between two points
+func lerpVector3(a, b Vector3, t float64) Vector3 {
+	return Vector3{
+		X: a.X + (b.X-a.X)*t,
+		Y: a.Y + (b.Y-a.Y)*t,
+		Z: a.Z + (b.Z-a.Z)*t,
+	}
+}
+
+// lerpFloat linearly interpolates between two values
+func lerpFloat(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// approx reports whether two screen coordinates match to a thousandth of a pixel
+func approx(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-3
+}
+
+// TestProjectVertex rotates vertices and projects them the way drawTexturedCube does
+func TestProjectVertex(t *testing.T) {
+	const (
+		fov     = 300.0
+		zOffset = 300.0
+		cx      = 160
+		cy      = 100
+	)
+
+	tests := []struct {
+		name  string
+		v     Vector3
+		rot   Vector3
+		wantX float32
+		wantY float32
+	}{
+		// At z = 0 the depth is fov + zOffset = 600, so the scale is 0.5
+		{"no rotation", Vector3{X: 100, Y: 50}, Vector3{}, cx + 50, cy + 25},
+		{"x quarter turn", Vector3{Y: 100, Z: 100}, Vector3{X: math.Pi / 2}, cx, cy - 100*fov/700},
+		{"y quarter turn", Vector3{X: 100, Y: 100}, Vector3{Y: math.Pi / 2}, cx, cy + 100*fov/500},
+		{"z quarter turn", Vector3{X: 100}, Vector3{Z: math.Pi / 2}, cx, cy + 50},
+		{"z eighth turn", Vector3{X: 100}, Vector3{Z: math.Pi / 4}, cx + 25*math.Sqrt2, cy + 25*math.Sqrt2},
+		{"half turn", Vector3{X: 100, Y: 50}, Vector3{Y: math.Pi}, cx - 50, cy + 25},
+		// X is applied before Y: (0, 100, 0) goes to (0, 0, 100), then to (100, 0, 0)
+		{"x then y", Vector3{Y: 100}, Vector3{X: math.Pi / 2, Y: math.Pi / 2}, cx + 50, cy},
+		// Behind the camera the depth is clamped, and the scale with it
+		{"behind the camera", Vector3{X: 1, Y: -1, Z: -1000}, Vector3{}, cx + cubeMaxScale, cy - cubeMaxScale},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y := projectPoint(rotationMatrix(tt.rot).apply(tt.v), fov, zOffset, cx, cy)
+			if !approx(x, tt.wantX) || !approx(y, tt.wantY) {
+				t.Errorf("%v rotated by %v projects to (%.3f, %.3f), want (%.3f, %.3f)", tt.v, tt.rot, x, y, tt.wantX, tt.wantY)
+			}
+		})
+	}
+}
+
+func TestFaceVisible(t *testing.T) {
+	camera := Vector3{Z: -(defaultCubeFOV + defaultCubeDistance)}
+
+	// Near side of a cube, wound so its normal points towards the camera
+	p1 := Vector3{X: 100, Y: -100, Z: -100}
+	p2 := Vector3{X: -100, Y: -100, Z: -100}
+	p3 := Vector3{X: -100, Y: 100, Z: -100}
+
+	if !faceVisible(p1, p2, p3, camera) {
+		t.Errorf("face with its normal towards the camera is culled")
+	}
+	if faceVisible(p1, p3, p2, camera) {
+		t.Errorf("face with its normal away from the camera is drawn")
+	}
+}
+
+func TestFaceVisibleMatchesScreenWinding(t *testing.T) {
+	camera := Vector3{Z: -(defaultCubeFOV + defaultCubeDistance)}
+	triangle := [3]Vector3{{X: -80, Y: -60, Z: 50}, {X: 90, Y: -40, Z: -30}, {X: 10, Y: 90, Z: 20}}
+
+	for i := 0; i < 64; i++ {
+		rot := Vector3{X: float64(i) * 0.37, Y: float64(i) * 0.59, Z: float64(i) * 0.23}
+		m := rotationMatrix(rot)
+
+		var p [3]Vector3
+		var screen [3][2]float32
+		for j, v := range triangle {
+			p[j] = m.apply(v)
+			screen[j][0], screen[j][1] = projectPoint(p[j], defaultCubeFOV, defaultCubeDistance, 0, 0)
+		}
+
+		// Signed area of the projected triangle, negative when counter-clockwise on the y-down screen
+		area := (screen[1][0]-screen[0][0])*(screen[2][1]-screen[0][1]) -
+			(screen[1][1]-screen[0][1])*(screen[2][0]-screen[0][0])
+		if math.Abs(float64(area)) < 1 {
+			continue // Edge-on, either answer is fine
+		}
+		if got, want := faceVisible(p[0], p[1], p[2], camera), area < 0; got != want {
+			t.Errorf("rotation %v: faceVisible = %v, projected winding says %v", rot, got, want)
+		}
+	}
+}
+
+// TestCubeFacesVisibleThroughRotation turns the cube mesh through full rotations and checks
+// that exactly its front faces are drawn: those whose plane has the camera on its outer side,
+// found from the face centers so the check doesn't depend on the winding. In perspective a
+// cube shows one, two or three faces depending on the angle, never two opposite ones.
+func TestCubeFacesVisibleThroughRotation(t *testing.T) {
+	vertices, faces, err := parseOBJ(cubeOBJData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(faces) != 6 {
+		t.Fatalf("cube mesh has %d faces, want 6", len(faces))
+	}
+	camera := Vector3{Z: -(defaultCubeFOV + defaultCubeDistance)}
+
+	const steps = 720
+	for i := 0; i < steps; i++ {
+		angle := 2 * math.Pi * float64(i) / steps
+		rot := Vector3{X: angle, Y: 2 * angle, Z: 3 * angle}
+		m := rotationMatrix(rot)
+
+		var transformed []Vector3
+		for _, v := range vertices {
+			transformed = append(transformed, m.apply(Vector3{X: v.X * 100, Y: v.Y * 100, Z: v.Z * 100}))
+		}
+
+		visible := 0
+		var centers []Vector3
+		for _, face := range faces {
+			p1, p2, p3, p4 := transformed[face.P1], transformed[face.P2], transformed[face.P3], transformed[face.P4]
+			center := Vector3{X: (p1.X + p3.X) / 2, Y: (p1.Y + p3.Y) / 2, Z: (p1.Z + p3.Z) / 2}
+			centers = append(centers, center)
+
+			// The cube is centered on the origin, so the face center is along its outward normal
+			front := center.dot(camera.sub(center))
+			if math.Abs(front) < 1 {
+				continue // Edge-on, either answer is fine
+			}
+			got := faceVisible(p1, p2, p3, camera)
+			if got != (front > 0) {
+				t.Fatalf("rotation %v: face %v drawn = %v, facing the camera = %v", rot, face, got, front > 0)
+			}
+			if faceVisible(p1, p3, p4, camera) != got {
+				t.Fatalf("rotation %v: face %v is not planar in its winding", rot, face)
+			}
+			if got {
+				visible++
+			}
+		}
+		if visible < 1 || visible > 3 {
+			t.Fatalf("rotation %v: %d faces drawn, want 1 to 3", rot, visible)
+		}
+
+		// Opposite faces are never drawn together
+		for a := range faces {
+			for b := a + 1; b < len(faces); b++ {
+				sum := Vector3{X: centers[a].X + centers[b].X, Y: centers[a].Y + centers[b].Y, Z: centers[a].Z + centers[b].Z}
+				if sum.dot(sum) < 1e-6 && faceVisible(transformed[faces[a].P1], transformed[faces[a].P2], transformed[faces[a].P3], camera) &&
+					faceVisible(transformed[faces[b].P1], transformed[faces[b].P2], transformed[faces[b].P3], camera) {
+					t.Fatalf("rotation %v: opposite faces %d and %d both drawn", rot, a, b)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,1561 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	// Screen dimensions
+	screenWidth  = 768
+	screenHeight = 540
+
+	// Canvas dimensions
+	stCanvasWidth  = 640
+	stCanvasHeight = 400
+
+	// Fixed animation timestep, tuned for the original 60 TPS
+	animationStep     = 1.0 / 60
+	maxAnimationSteps = 5 // Steps run at most per Update, to catch up after a slow frame
+
+	// Animation parameters
+	fadeSpeed     = 0.03
+	scrollSpeed   = 4.0
+	rotationSpeed = 0.05
+	zoomSpeed     = 0.01
+	plasmaSpeed   = 0.02
+	posSpeed      = 0.6 // Advance of the animation position per second
+
+	// Music volume change per key press
+	volumeStep = 0.05
+
+	// Scale of the mute indicator in the top right corner
+	muteFontScale = 0.5
+
+	// Music seeking: jump per key press, and ticks the time readout stays up after a seek
+	seekStep         = 5 * time.Second
+	seekReadoutTicks = 90
+
+	// Ticks the music and picture take to fade out when quitting
+	quitFadeTicks = 30
+
+	// Seconds of main demo before attract mode loops back when no tune can end it
+	attractFallbackDuration = 180.0
+
+	// Debug overlay parameters
+	debugFontScale    = 0.4
+	debugFrameSamples = 60    // Frames in the rolling average frame time
+	debugGraphWidth   = 120.0 // Frame time graph size in pixels
+	debugGraphHeight  = 30.0
+	debugGraphMaxMs   = 50.0 // Frame time at the top of the graph
+
+	// Height of the music progress bar in pixels
+	progressBarHeight = 3
+
+	// Music sync parameters
+	energyCoupling  = 0.6   // How strongly the music energy modulates the effects
+	beatThreshold   = 1.35  // Energy to average ratio that counts as a beat
+	beatMinEnergy   = 0.02  // Energy below which no beat is detected
+	beatDecay       = 0.85  // Per-frame decay of the beat flash
+	shakeSettle     = 0.1   // Shake in pixels below which the composite snaps back to the center
+	envelopeAttack  = 0.005 // Envelope follower attack time in seconds
+	envelopeRelease = 0.15  // Envelope follower release time in seconds
+	envelopeAverage = 1.0   // Long-term loudness average time in seconds
+)
+
+// Game represents the main demo state
+type Game struct {
+	// Settings the demo was started with
+	cfg Config
+
+	// Timeline of the demo parts
+	sequencer *Sequencer
+
+	// Logical screenWidth×screenHeight frame, scaled into the window viewport
+	frame    *ebiten.Image
+	frameOp  *ebiten.DrawImageOptions
+	viewport image.Rectangle
+
+	// Last window frame, shown again while the effects are held in the background
+	held    *ebiten.Image
+	holding bool // held has the frame of the current stay in the background
+
+	// Images
+	fontImg       *ebiten.Image
+	teamG1Logo    *ebiten.Image
+	gameOneLogo   *ebiten.Image
+	texture       *ebiten.Image
+	texturePixels *image.RGBA     // CPU copy of texture for the software effects
+	textures      []*ebiten.Image // Cube face textures, indexed by Face.TextureID
+
+	// Canvases
+	stCanvas     *ebiten.Image
+	plasmaCanvas *ebiten.Image
+	fireCanvas   *ebiten.Image
+	tunnelCanvas *ebiten.Image
+	cubeCanvas   *ebiten.Image
+	scrollCanvas *ebiten.Image
+	logoCanvas   *ebiten.Image
+	bloomCanvas  *ebiten.Image
+
+	// Effects
+	background  Background
+	plasmaField *PlasmaField
+	starfield   *Starfield
+	fire        *FireEffect
+	tunnel      *TunnelEffect
+	logoDistort *LogoDistortion
+
+	// Copper bars, one shaded strip per configured color
+	copperBars   bool
+	copperImages []*ebiten.Image
+
+	// Twister column
+	twister bool
+
+	// 3D Textured cube
+	cubeVertices []Vector3
+	cubeFaces    []Face
+	cubeRotation Vector3
+	solid        Solid
+
+	// Mouse control of the cube: dragging spins it, and on release it keeps
+	// turning with friction until A returns it to auto-rotation
+	cubeManual   bool
+	cubeDragging bool
+	dragX, dragY int
+	cubeSpin     Vector3 // Rotation per animation step
+
+	// Cube instances sharing the mesh, positioned around the canvas center
+	cubes    []CubeInstance
+	cubeGrid bool
+
+	// Subdivide faces for perspective-correct texturing
+	perspectiveCorrect bool
+
+	// Software z-buffer rendering of the cube
+	zBuffer    bool
+	cubeRaster *DepthRaster
+
+	// Cube projection, adjustable at runtime
+	cubeFOV      float64
+	cubeDistance float64
+
+	// Logo spiral
+	logoPositions []Vector3
+	logoCount     int
+	logoTime      float64
+	logoHueCycle  bool // Tint the spiral logos with a cycling rainbow
+
+	// Scrolling for demo (TCB style)
+	scrollText      string
+	scrollTextRunes []rune
+	scrollChars     []ScrollChar // scrollTextRunes laid out, x relative to the start of the text
+	scrollWidth     float64      // Advance width of scrollTextRunes at demoFontScale
+	scrollX         float64
+	scrollOffset    float64
+	scrollMode      ScrollMode
+	scrollReverse   bool          // Move the text left to right instead of right to left
+	scrollRainbow   bool          // Tint each scroller character with a cycling hue
+	scrollFringe    bool          // Red and blue fringes on each wave line, as wide as its offset
+	scrollGradient  *ebiten.Image // One pixel wide gradient, one row per scroller line
+	bounceCanvas    *ebiten.Image // Scroller band with room for the bouncing characters
+	scrollWave      []float64
+
+	// Typewriter mode: the scroll text typed in place one line at a time
+	typewriterLines [][]ScrollChar
+	typewriterLine  int
+	typewriterShown float64 // Characters of the current line revealed so far
+	typewriterHold  int     // Frames the completed line has been shown
+
+	// Animation state
+	pos           float64
+	shaderTime    float64 // CRT shader clock, running through every scene
+	introComplete bool
+	paused        bool
+	demoTime      float64 // Seconds of main demo played
+
+	// Fixed timestep: wall-clock time not yet consumed by animation steps
+	lastUpdate  time.Time
+	accumulator float64
+
+	// Key of each action
+	keys KeyBindings
+
+	// Gamepads connected this tick
+	gamepadIDs []ebiten.GamepadID
+
+	// Ticks left in the fade-out before quitting, 0 when not quitting
+	quitTicks int
+
+	// Screenshot requested in Update, taken at the end of Draw
+	wantScreenshot bool
+
+	// Music progress bar along the bottom of the main demo
+	showProgress bool
+
+	// Debug overlay with FPS, TPS and a rolling average frame time
+	showDebug  bool
+	lastFrame  time.Time
+	frameTimes [debugFrameSamples]float64 // Milliseconds between Draw calls
+	frameIndex int
+	frameCount int
+
+	// Music sync
+	musicEnergy float64
+	beatFlash   float64
+	shake       float64 // Current shake magnitude in pixels
+	shakeX      float64 // Offset of the final composite
+	shakeY      float64
+
+	// Music credits shown at the bottom of the intro
+	creditsRunes []rune
+	creditsWidth float64
+	creditsX     float64
+
+	// Audio
+	sampleRate   int
+	volume       float64
+	audioContext *audio.Context
+	audioPlayer  *audio.Player
+	lowPass      bool        // Low-pass filter on the YM output
+	muted        bool        // Music silenced, keeping the volume for when it comes back
+	musicPaused  bool        // Music held at its position while the effects go on
+	seekReadout  int         // Ticks left showing the music time after a seek
+	music        MusicSource // YM tune, or the fallback tone when it fails to load
+	trackEnded   atomic.Bool // Set from the audio goroutine when the tune plays out in attract mode
+
+	// Shader
+	crtShader  *ebiten.Shader
+	crtEnabled bool // Apply the CRT shader to the intro scroll
+	crtDemo    bool // Apply the CRT shader to the main demo composite
+	crtParams  CRTParams
+	crtParam   int // Index of the CRT parameter adjusted with Page Up / Page Down
+
+	// External CRT shader source, reloaded when its modification time changes
+	shaderPath    string
+	shaderModTime time.Time
+	shaderPoll    int
+
+	// Bloom post-processing of the main demo
+	bloomShader    *ebiten.Shader
+	bloomEnabled   bool
+	bloomThreshold float64
+	bloomIntensity float64
+
+	// Aperture-grille mask multiplied over the final image, nil when disabled
+	phosphorMask *ebiten.Image
+
+	// Gamma correction of the final image, nil at gamma 1
+	gammaShader *ebiten.Shader
+
+	// Font data
+	letterData map[rune]*Letter
+
+	// Intro tickers: the main scroll, whose end finishes the intro, and the looping
+	// bottom ticker, nil when it has no text
+	intro       *Ticker
+	introTicker *Ticker
+
+	// Draw options (optimization)
+	drawOp     *ebiten.DrawImageOptions
+	drawRectOp *ebiten.DrawRectShaderOptions
+
+	// Random source of the shake, and of the generators handed to the other random effects
+	rng *rand.Rand
+}
+
+// newRand returns a generator for one random effect, seeded from the game's, so each effect
+// replays the same sequence for a given seed however much the others draw
+func (g *Game) newRand() *rand.Rand {
+	return rand.New(rand.NewSource(g.rng.Int63()))
+}
+
+// NewGame creates and initializes a new game instance
+func NewGame(cfg Config) *Game {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+		log.Printf("Random seed %d (run with -seed %d to replay the same effects)", seed, seed)
+	}
+
+	g := &Game{
+		cfg:           cfg,
+		sampleRate:    cfg.SampleRate,
+		volume:        cfg.Volume,
+		showDebug:     cfg.Debug,
+		showProgress:  cfg.ShowProgress,
+		letterData:    make(map[rune]*Letter),
+		keys:          newKeyBindings(cfg.Keys),
+		scrollMode:    scrollModes[cfg.ScrollMode],
+		scrollReverse: cfg.ScrollReverse,
+		scrollFringe:  cfg.ScrollFringe,
+		rng:           rand.New(rand.NewSource(seed)),
+		drawOp:        &ebiten.DrawImageOptions{},
+		drawRectOp:    &ebiten.DrawRectShaderOptions{},
+
+		crtEnabled:         true,
+		crtParams:          defaultCRTParams,
+		bloomEnabled:       cfg.Bloom,
+		bloomThreshold:     defaultBloomThreshold,
+		bloomIntensity:     defaultBloomIntensity,
+		perspectiveCorrect: true,
+		cubeFOV:            defaultCubeFOV,
+		cubeDistance:       defaultCubeDistance,
+		logoCount:          cfg.LogoCount,
+		logoHueCycle:       true,
+		logoTime:           0,
+		scrollWave:         make([]float64, 0),
+	}
+
+	// Load images
+	g.loadImages()
+	g.textures = []*ebiten.Image{g.texture, g.teamG1Logo, g.gameOneLogo}
+
+	// Create canvases
+	g.frame = ebiten.NewImage(screenWidth, screenHeight)
+	g.frameOp = &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear}
+	g.viewport = image.Rect(0, 0, screenWidth, screenHeight)
+	g.stCanvas = ebiten.NewImage(stCanvasWidth, stCanvasHeight)
+	plasmaWidth, plasmaHeight := stCanvasWidth/2, stCanvasHeight/2
+	if cfg.PlasmaFullRes {
+		plasmaWidth, plasmaHeight = stCanvasWidth, stCanvasHeight
+	}
+	g.plasmaCanvas = ebiten.NewImage(plasmaWidth, plasmaHeight)
+	g.fireCanvas = ebiten.NewImage(stCanvasWidth/2, stCanvasHeight/2)
+	g.tunnelCanvas = ebiten.NewImage(stCanvasWidth/2, stCanvasHeight/2)
+	g.cubeCanvas = ebiten.NewImage(stCanvasWidth, stCanvasHeight)
+	g.cubeRaster = NewDepthRaster(stCanvasWidth, stCanvasHeight)
+	g.scrollCanvas = ebiten.NewImage(stCanvasWidth+512, int(fontHeight*demoFontScale))
+	g.bounceCanvas = ebiten.NewImage(stCanvasWidth, int(fontHeight*demoFontScale)+2*bounceAmplitude)
+	g.scrollGradient = newGradientImage(cfg.GradientTop, cfg.GradientBottom, int(fontHeight*demoFontScale))
+	g.logoCanvas = ebiten.NewImage(stCanvasWidth, stCanvasHeight)
+	g.bloomCanvas = ebiten.NewImage(stCanvasWidth, stCanvasHeight)
+
+	// Intro texts, fed in at the right edge of the ST canvas for the main scroll
+	// and at the screen edge for the bottom ticker
+	g.intro = NewTicker([]rune(fontText(scrollPadding+cfg.IntroText+scrollPadding)), stCanvasWidth, introFontScale, cfg.IntroScrollSpeed, false)
+	if cfg.IntroTicker != "" {
+		g.introTicker = NewTicker([]rune(fontText(cfg.IntroTicker+scrollPadding)), screenWidth, tickerFontScale, cfg.IntroTickerSpeed, true)
+	}
+
+	// Initialize font data, dropping glyphs a replacement font image doesn't cover
+	g.initFontData()
+	g.validateFontData()
+
+	// Main demo text
+	g.SetScrollText(cfg.ScrollText)
+
+	// Initialize 3D textured cube
+	g.initCube()
+	g.cubes = singleCube()
+
+	// Initialize logo spiral positions
+	g.initLogoSpiral()
+
+	// Initialize plasma effect; at full resolution the same pattern gets twice the pixels
+	g.plasmaField = &PlasmaField{
+		width:      plasmaWidth,
+		height:     plasmaHeight,
+		detail:     float64(plasmaWidth) / (stCanvasWidth / 2),
+		buffer:     g.plasmaCanvas,
+		pixels:     make([]byte, 4*plasmaWidth*plasmaHeight),
+		indices:    make([]uint8, plasmaWidth*plasmaHeight),
+		workers:    runtime.NumCPU(),
+		op:         &ebiten.DrawRectShaderOptions{},
+		paletteImg: ebiten.NewImage(plasmaWidth, plasmaHeight),
+	}
+	g.plasmaField.setPalette(PaletteClassic)
+
+	// Initialize the other backgrounds
+	g.background = backgrounds[cfg.Background]
+	g.starfield = NewStarfield(cfg.StarCount, cfg.StarSpeed, g.newRand())
+	g.fire = NewFireEffect(g.fireCanvas, cfg.FireIntensity, cfg.FireCooling, g.newRand())
+	g.tunnel = NewTunnelEffect(g.tunnelCanvas, g.texturePixels, cfg.TunnelSpeed, cfg.TunnelTwist)
+
+	// Initialize copper bars
+	g.copperBars = cfg.CopperBars
+	g.twister = cfg.Twister
+	g.lowPass = cfg.LowPass
+	for _, c := range cfg.CopperColors {
+		g.copperImages = append(g.copperImages, newCopperBarImage(c))
+	}
+
+	// Initialize logo distortion
+	g.initLogoDistortion()
+
+	// Initialize audio
+	if !cfg.NoAudio {
+		g.initAudio()
+	}
+
+	// Initialize music credits
+	g.initCredits()
+
+	if cfg.PhosphorMask > 0 {
+		g.phosphorMask = newPhosphorMask(cfg.PhosphorMask)
+	}
+
+	// Compile CRT shader
+	var err error
+	g.crtShader, err = ebiten.NewShader([]byte(crtShaderSrc))
+	if err != nil {
+		log.Printf("Failed to compile CRT shader: %v", err)
+	}
+
+	// Compile bloom shader, skipping the bloom pass on failure
+	g.bloomShader, err = ebiten.NewShader([]byte(bloomShaderSrc))
+	if err != nil {
+		log.Printf("Failed to compile bloom shader: %v", err)
+	}
+
+	// Compile the gamma shader only when the gamma is corrected, skipping it on failure
+	if cfg.Gamma != 1 {
+		g.gammaShader, err = ebiten.NewShader([]byte(gammaShaderSrc))
+		if err != nil {
+			log.Printf("Failed to compile gamma shader: %v", err)
+		}
+	}
+
+	// Compile plasma shader, falling back to the CPU plasma on failure
+	g.plasmaField.shader, err = ebiten.NewShader([]byte(plasmaShaderSrc))
+	if err != nil {
+		log.Printf("Failed to compile plasma shader, using CPU plasma: %v", err)
+	}
+
+	// Load an external CRT shader over the built-in one
+	if cfg.ShaderPath != "" {
+		g.setShaderPath(cfg.ShaderPath)
+	}
+
+	// Start every animation from the beginning
+	g.resetState()
+
+	// Demo timeline: the intro scroll, then the main demo until the program exits
+	g.sequencer = NewSequencer(g.newRand(),
+		SequenceEntry{Scene: &introScene{g: g}},
+		SequenceEntry{Scene: &demoScene{g: g}, Transition: Transition{
+			Mode:   transitionModes[cfg.Transition],
+			Frames: cfg.TransitionFrames,
+		}},
+	)
+
+	// Start directly with the main demo
+	if cfg.StartScene == sceneDemo {
+		g.skipIntro()
+	}
+
+	return g
+}
+
+// loadImages loads all image assets
+func (g *Game) loadImages() {
+	// Load font
+	img, err := decodeAsset(g.cfg.AssetsDir, "font.png", fontData)
+	if err != nil {
+		log.Printf("Failed to load font, using the built-in fallback glyphs: %v", err)
+		g.fontImg = ebiten.NewImageFromImage(newFallbackFont(480, 216))
+	} else {
+		g.fontImg = ebiten.NewImageFromImage(img)
+	}
+
+	// Load TEAMG1 logo
+	img, err = decodeAsset(g.cfg.AssetsDir, "teamg1_logo.png", teamG1LogoData)
+	if err != nil {
+		log.Printf("Failed to load TEAMG1 logo: %v", err)
+		g.teamG1Logo = ebiten.NewImage(256, 64)
+		g.teamG1Logo.Fill(color.RGBA{255, 0, 255, 255})
+	} else {
+		g.teamG1Logo = ebiten.NewImageFromImage(img)
+	}
+
+	// Load GAMEONE logo
+	img, err = decodeAsset(g.cfg.AssetsDir, "gameone_logo.png", gameOneLogoData)
+	if err != nil {
+		log.Printf("Failed to load GAMEONE logo: %v", err)
+		g.gameOneLogo = ebiten.NewImage(64, 64)
+		g.gameOneLogo.Fill(color.RGBA{0, 255, 255, 255})
+	} else {
+		g.gameOneLogo = ebiten.NewImageFromImage(img)
+	}
+
+	// Load texture, keeping a CPU copy for the software effects
+	img, err = decodeAsset(g.cfg.AssetsDir, "texture.png", textureData)
+	if err != nil {
+		log.Printf("Failed to load texture: %v", err)
+		checker := image.NewRGBA(image.Rect(0, 0, 256, 256))
+		// Create a procedural checkerboard texture
+		for y := 0; y < 256; y++ {
+			for x := 0; x < 256; x++ {
+				if (x/32+y/32)%2 == 0 {
+					checker.Set(x, y, color.RGBA{255, 0, 255, 255})
+				} else {
+					checker.Set(x, y, color.RGBA{0, 255, 255, 255})
+				}
+			}
+		}
+		img = checker
+	}
+	g.texturePixels = toRGBA(img)
+	g.texture = ebiten.NewImageFromImage(g.texturePixels)
+}
+
+// decodeAsset decodes the named image from the assets directory dir when one is set, falling back
+// to the embedded copy when the file is missing or can't be decoded
+func decodeAsset(dir, name string, embedded []byte) (image.Image, error) {
+	if dir != "" {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if err == nil {
+			img, _, err := image.Decode(bytes.NewReader(data))
+			if err == nil {
+				return img, nil
+			}
+			log.Printf("Failed to decode %s, using the embedded image: %v", path, err)
+		} else if !os.IsNotExist(err) {
+			log.Printf("Failed to read %s, using the embedded image: %v", path, err)
+		}
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(embedded))
+	return img, err
+}
+
+// toRGBA returns img as an *image.RGBA with its origin at 0, 0
+func toRGBA(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+	return rgba
+}
+
+// initAudio initializes the audio system with YM music
+func (g *Game) initAudio() {
+	g.audioContext = audio.NewContext(g.sampleRate)
+
+	ymPlayer, err := NewYMPlayer(musicData, g.sampleRate, true)
+	if err != nil {
+		log.Printf("Failed to create YM player, playing a fallback tone instead: %v", err)
+		g.music = NewToneGenerator(g.sampleRate)
+	} else {
+		g.useTune(ymPlayer)
+	}
+
+	g.audioPlayer, err = g.audioContext.NewPlayer(g.music)
+	if err != nil {
+		log.Printf("Failed to create audio player: %v", err)
+		g.music.Close()
+		g.music = nil
+		return
+	}
+
+	g.audioPlayer.SetVolume(g.volume)
+}
+
+// useTune sets up the YM player and plays it as the music
+func (g *Game) useTune(ymPlayer *YMPlayer) {
+	ymPlayer.SetLowPass(g.lowPass, g.cfg.LowPassCutoff)
+	ymPlayer.SetStereoWidth(g.cfg.StereoWidth)
+	if g.cfg.Attract && g.cfg.AttractDuration == 0 {
+		// Play the tune once and loop the whole demo when it ends
+		ymPlayer.SetLoopLimit(1)
+		ymPlayer.SetOnEnd(func() { g.trackEnded.Store(true) })
+	}
+	g.music = ymPlayer
+}
+
+// animIntro handles intro animation
+func (g *Game) animIntro() {
+	g.updateTicker(g.intro)
+	if g.intro.done {
+		g.introComplete = true
+		return
+	}
+	if g.introTicker != nil {
+		g.updateTicker(g.introTicker)
+	}
+
+	g.updateCredits()
+}
+
+// resetState puts every animation back at its starting point.
+// It holds the state shared by NewGame and restart.
+func (g *Game) resetState() {
+	// Intro
+	g.intro.Reset()
+	if g.introTicker != nil {
+		g.introTicker.Reset()
+	}
+	g.introComplete = false
+	g.shaderTime = 0
+	g.creditsX = screenWidth
+
+	// Main demo
+	g.demoTime = 0
+	g.pos = 0
+	g.scrollX = 0
+	g.scrollOffset = 0
+	g.resetScrollChars()
+	g.resetTypewriter()
+	g.cubeRotation = Vector3{}
+	g.cubeSpin = Vector3{}
+	g.logoTime = 0
+	g.logoDistort.distCount = 0
+	g.plasmaField.time = 0
+	g.plasmaField.cycleOffset = 0
+	g.plasmaField.patternReady = false
+
+	// Music sync
+	g.musicEnergy = 0
+	g.beatFlash = 0
+	g.shake = 0
+	g.shakeX, g.shakeY = 0, 0
+}
+
+// restart plays the whole demo again from the intro, with the music back at its start
+func (g *Game) restart() {
+	if g.audioPlayer != nil {
+		g.audioPlayer.Pause()
+		if err := g.audioPlayer.Rewind(); err != nil {
+			log.Printf("Failed to rewind music: %v", err)
+		}
+	}
+
+	g.paused = false
+	g.musicPaused = false
+	g.lastUpdate = time.Time{}
+	g.trackEnded.Store(false)
+	g.resetState()
+	g.sequencer.Restart()
+}
+
+// attractDue reports whether attract mode should loop back to the intro: after the
+// configured duration, or else once the tune has played out. Without a tune that can
+// end, such as the fallback tone, it loops after attractFallbackDuration.
+func (g *Game) attractDue() bool {
+	if !g.cfg.Attract {
+		return false
+	}
+	if g.cfg.AttractDuration > 0 {
+		return g.demoTime >= g.cfg.AttractDuration
+	}
+	if _, ok := g.music.(*YMPlayer); ok {
+		return g.trackEnded.Load()
+	}
+	return g.demoTime >= attractFallbackDuration
+}
+
+// skipIntro ends the intro scroll so the sequencer moves on to the main demo
+func (g *Game) skipIntro() {
+	g.introComplete = true
+
+	// Drop the fed letters so no intro text bleeds into the main scene
+	g.intro.Finish()
+	if g.introTicker != nil {
+		g.introTicker.Finish()
+	}
+}
+
+// updateMainDemo advances all main demo animations by one frame
+func (g *Game) updateMainDemo() {
+	// Update the background shown
+	switch g.background {
+	case BackgroundStarfield:
+		g.starfield.Update()
+	case BackgroundFire:
+		g.fire.Update()
+	case BackgroundTunnel:
+		g.tunnel.Update()
+	default:
+		g.updatePlasma()
+	}
+
+	// Update effects
+	g.demoTime += animationStep
+	g.pos += posSpeed * animationStep
+
+	// Update cube rotation
+	if !g.cubeManual {
+		g.cubeRotation.X += g.cfg.CubeRotationSpeed.X
+		g.cubeRotation.Y += g.cfg.CubeRotationSpeed.Y
+		g.cubeRotation.Z += g.cfg.CubeRotationSpeed.Z
+	} else if !g.cubeDragging {
+		// Inertia after a drag
+		g.cubeRotation = g.cubeRotation.add(g.cubeSpin)
+		g.cubeSpin = g.cubeSpin.scale(cubeFriction)
+	}
+
+	// Update logo distortion counter and spiral
+	g.logoDistort.distCount += g.logoDistort.speed
+	g.logoTime += 0.02
+
+	// Update scroll position, or the typewriter in its place
+	if g.scrollMode == ScrollModeTypewriter {
+		g.updateTypewriter()
+	} else {
+		g.scrollX += g.cfg.ScrollSpeed
+
+		// Reset when scrolled completely off
+		if g.scrollX >= g.scrollWidth {
+			g.scrollX = 0
+		}
+	}
+	if g.scrollMode == ScrollModeBounce {
+		g.updateBounce()
+	}
+
+	// Update wave offset
+	g.scrollOffset += 0.5
+}
+
+// drawMainDemo draws the main demo scene
+func (g *Game) drawMainDemo() {
+	// Clear main canvas
+	g.stCanvas.Fill(color.Black)
+
+	// Draw the background
+	switch g.background {
+	case BackgroundStarfield:
+		g.starfield.Draw(g.stCanvas, g.cubeFOV)
+	case BackgroundFire:
+		// Fire, scaled up like the plasma
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(2, 2)
+		g.stCanvas.DrawImage(g.fireCanvas, op)
+	case BackgroundTunnel:
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(2, 2)
+		g.stCanvas.DrawImage(g.tunnelCanvas, op)
+	case BackgroundRotozoom:
+		g.drawRotozoom()
+	default:
+		// Plasma, scaled up unless rendered at full resolution. updatePlasma has already
+		// built the whole frame in Update, which Ebiten always runs before Draw, so the
+		// buffer holds a complete frame here.
+		op := &ebiten.DrawImageOptions{}
+		if !g.cfg.PlasmaFullRes {
+			op.GeoM.Scale(2, 2)
+		}
+		g.stCanvas.DrawImage(g.plasmaCanvas, op)
+	}
+
+	// Draw the twister column between the background and the cube
+	if g.twister {
+		g.drawTwister()
+	}
+
+	// Draw textured cube
+	g.drawTexturedCube()
+	op := &ebiten.DrawImageOptions{}
+	op.ColorScale.ScaleAlpha(0.8)
+	g.stCanvas.DrawImage(g.cubeCanvas, op)
+
+	// Draw copper bars, then the distorted TEAMG1 logo over them
+	if g.copperBars {
+		g.drawCopperBars()
+	}
+	g.drawDistortedLogo()
+
+	// Draw scrolling text
+	g.drawScrollText()
+
+	// Draw logo spiral
+	g.drawLogoSpiral()
+	op = &ebiten.DrawImageOptions{}
+	op.ColorScale.ScaleAlpha(0.6)
+	g.stCanvas.DrawImage(g.logoCanvas, op)
+
+}
+
+// Update updates the game state
+func (g *Game) Update() error {
+	// Gamepads can come and go between frames
+	g.gamepadIDs = ebiten.AppendGamepadIDs(g.gamepadIDs[:0])
+
+	g.checkAudioError()
+
+	// Quit after fading the music and picture out; a second press quits at once.
+	// Returning ebiten.Termination ends RunGame normally, so Cleanup runs.
+	if g.justPressed(ActionQuit) {
+		if g.quitTicks > 0 {
+			return ebiten.Termination
+		}
+		g.quitTicks = quitFadeTicks
+	}
+	if g.quitTicks > 0 {
+		g.quitTicks--
+		if g.audioPlayer != nil {
+			g.audioPlayer.SetVolume(g.volume * float64(g.quitTicks) / quitFadeTicks)
+		}
+		if g.quitTicks == 0 {
+			return ebiten.Termination
+		}
+	}
+
+	// Handle fullscreen toggle
+	if g.justPressed(ActionFullscreen) || g.gamepadJustPressed(ebiten.StandardGamepadButtonCenterLeft) {
+		ebiten.SetFullscreen(!ebiten.IsFullscreen())
+	}
+
+	// Music volume
+	if g.justPressed(ActionVolumeUp) || g.gamepadJustPressed(ebiten.StandardGamepadButtonLeftTop) {
+		g.setVolume(g.volume + volumeStep)
+	}
+	if g.justPressed(ActionVolumeDown) || g.gamepadJustPressed(ebiten.StandardGamepadButtonLeftBottom) {
+		g.setVolume(g.volume - volumeStep)
+	}
+
+	// Mute the music without touching the volume
+	if g.justPressed(ActionMute) {
+		g.muted = !g.muted
+		if g.music != nil {
+			g.music.SetMuted(g.muted)
+		}
+	}
+
+	// Toggle the low-pass filter on the YM output
+	if g.justPressed(ActionLowPass) {
+		g.lowPass = !g.lowPass
+		if ymPlayer, ok := g.music.(*YMPlayer); ok {
+			ymPlayer.SetLowPass(g.lowPass, g.cfg.LowPassCutoff)
+		}
+	}
+
+	// Cycle plasma palettes
+	if g.justPressed(ActionPalette) {
+		g.plasmaField.setPalette((g.plasmaField.Palette + 1) % paletteCount)
+	}
+
+	// Cycle the main demo backgrounds
+	if g.justPressed(ActionBackground) {
+		g.background = (g.background + 1) % backgroundCount
+	}
+
+	// Toggle the copper bars
+	if g.justPressed(ActionCopperBars) {
+		g.copperBars = !g.copperBars
+	}
+
+	// Toggle the twister column
+	if g.justPressed(ActionTwister) {
+		g.twister = !g.twister
+	}
+
+	// Toggle plasma palette cycling
+	if g.justPressed(ActionPaletteCycling) {
+		g.plasmaField.toggleCycling()
+	}
+
+	// Toggle perspective-correct cube texturing
+	if g.justPressed(ActionPerspective) {
+		g.perspectiveCorrect = !g.perspectiveCorrect
+	}
+
+	// Toggle the software z-buffer for the cube
+	if g.justPressed(ActionZBuffer) {
+		g.zBuffer = !g.zBuffer
+	}
+
+	// Tune the logo distortion amplitude and speed
+	if g.justPressed(ActionLogoAmplitudeDown) {
+		g.logoDistort.adjust(-logoAmplitudeStep, 0)
+	}
+	if g.justPressed(ActionLogoAmplitudeUp) {
+		g.logoDistort.adjust(logoAmplitudeStep, 0)
+	}
+	if g.justPressed(ActionLogoSpeedDown) {
+		g.logoDistort.adjust(0, -logoSpeedStep)
+	}
+	if g.justPressed(ActionLogoSpeedUp) {
+		g.logoDistort.adjust(0, logoSpeedStep)
+	}
+
+	// Toggle the CRT shader on the intro scroll
+	if g.justPressed(ActionIntroCRT) {
+		g.crtEnabled = !g.crtEnabled
+	}
+
+	// Toggle the CRT shader on the main demo
+	if g.justPressed(ActionDemoCRT) {
+		g.crtDemo = !g.crtDemo
+	}
+
+	// Tune the CRT shader parameters
+	g.updateCRTParams()
+
+	// Hot-reload the external CRT shader
+	g.pollCRTShader()
+
+	// Toggle the bloom pass
+	if g.justPressed(ActionBloom) {
+		g.bloomEnabled = !g.bloomEnabled
+	}
+
+	// Reverse the scroll direction, keeping the text where it is
+	if g.justPressed(ActionScrollDirection) {
+		g.scrollReverse = !g.scrollReverse
+		g.scrollX = g.scrollWidth - g.scrollX
+	}
+
+	// Cycle the scroller modes: wave, bounce, typewriter
+	if g.justPressed(ActionScrollMode) {
+		g.scrollMode = (g.scrollMode + 1) % scrollModeCount
+		g.resetScrollChars()
+		g.resetTypewriter()
+	}
+
+	// Toggle the chromatic fringes of the wave scroller
+	if g.justPressed(ActionScrollFringe) {
+		g.scrollFringe = !g.scrollFringe
+	}
+
+	// Toggle the rainbow scroller
+	if g.justPressed(ActionRainbow) {
+		g.scrollRainbow = !g.scrollRainbow
+	}
+
+	// Toggle the rainbow tint of the spiral logos
+	if g.justPressed(ActionLogoHue) {
+		g.logoHueCycle = !g.logoHueCycle
+	}
+
+	// Toggle the vertical logo ripple
+	if g.justPressed(ActionVerticalRipple) {
+		g.logoDistort.vertical = !g.logoDistort.vertical
+	}
+
+	// Toggle the chrome environment mapping on every cube instance
+	if g.justPressed(ActionEnvMap) {
+		for i := range g.cubes {
+			g.cubes[i].EnvMapped = !g.cubes[i].EnvMapped
+		}
+	}
+
+	// Cycle through the platonic solids
+	if g.justPressed(ActionSolid) {
+		g.setSolid((g.solid + 1) % solidCount)
+	}
+
+	// Switch between the single cube and the 3×3 cube grid
+	if g.justPressed(ActionCubeGrid) {
+		envMapped := g.cubes[0].EnvMapped
+		g.cubeGrid = !g.cubeGrid
+		if g.cubeGrid {
+			g.cubes = cubeGridLayout(3, 200)
+		} else {
+			g.cubes = singleCube()
+		}
+		for i := range g.cubes {
+			g.cubes[i].EnvMapped = envMapped
+		}
+	}
+
+	// Zoom the cube camera while = or - is held
+	g.updateCubeZoom()
+
+	// Spin the cube with the mouse, or give it back to auto-rotation
+	g.updateCubeDrag()
+	if g.justPressed(ActionAutoRotate) {
+		g.cubeManual = false
+		g.cubeDragging = false
+	}
+
+	// Request a screenshot of the next frame
+	if g.justPressed(ActionScreenshot) {
+		g.wantScreenshot = true
+	}
+
+	// Toggle the debug overlay
+	if g.justPressed(ActionDebug) {
+		g.showDebug = !g.showDebug
+	}
+
+	// Toggle the music progress bar
+	if g.justPressed(ActionProgress) {
+		g.showProgress = !g.showProgress
+	}
+
+	// Save the current settings
+	if g.justPressed(ActionSaveConfig) {
+		g.saveConfig()
+	}
+
+	// Restart the demo from the beginning
+	if g.justPressed(ActionRestart) {
+		g.restart()
+	}
+
+	// Toggle pause, freezing animation and audio
+	if g.justPressed(ActionPause) || g.gamepadJustPressed(ebiten.StandardGamepadButtonCenterRight) {
+		g.paused = !g.paused
+		if g.paused && g.audioPlayer != nil {
+			g.audioPlayer.Pause()
+		}
+	}
+
+	// Pause only the music, the effects going on without it
+	if g.justPressed(ActionMusicPause) {
+		g.musicPaused = !g.musicPaused
+		if g.musicPaused && g.audioPlayer != nil {
+			g.audioPlayer.Pause()
+		}
+	}
+
+	// Seek through the music, paused or not
+	if g.justPressed(ActionSeekBack) {
+		g.seekMusic(-seekStep)
+	}
+	if g.justPressed(ActionSeekForward) {
+		g.seekMusic(seekStep)
+	}
+	if g.seekReadout > 0 {
+		g.seekReadout--
+	}
+
+	// While paused, only input is handled; the music resumes with the main demo
+	if g.paused {
+		g.lastUpdate = time.Time{}
+		return nil
+	}
+
+	// Hold the effects in the background to save CPU, the last frame staying on screen
+	// and the music playing on
+	if g.inBackground() {
+		g.lastUpdate = time.Time{}
+		return nil
+	}
+
+	// Skip the intro scroll
+	if !g.introComplete && (g.justPressed(ActionSkipIntro) || g.gamepadJustPressed(ebiten.StandardGamepadButtonRightBottom)) {
+		g.skipIntro()
+	}
+
+	// Advance the animation in fixed steps matching the elapsed time
+	for steps := g.animationSteps(); steps > 0; steps-- {
+		g.sequencer.Update()
+		g.updateMusicSync()
+		g.shaderTime += animationStep
+	}
+
+	// Start over from the intro in attract mode
+	if g.attractDue() {
+		g.restart()
+	}
+
+	return nil
+}
+
+// inBackground reports whether the window is unfocused or minimized and the effects
+// should be held, unless the config asks to keep rendering in the background. Runs in
+// deterministic mode are never held: outside RunGame, such as in RunFrames and
+// RenderFrames, Ebiten reports the window as unfocused.
+func (g *Game) inBackground() bool {
+	if g.cfg.RenderUnfocused || g.cfg.Deterministic {
+		return false
+	}
+	return !ebiten.IsFocused() || ebiten.IsWindowMinimized()
+}
+
+// justPressed reports whether the key bound to the action was pressed this tick
+func (g *Game) justPressed(action Action) bool {
+	return inpututil.IsKeyJustPressed(g.keys[action])
+}
+
+// pressed reports whether the key bound to the action is held down
+func (g *Game) pressed(action Action) bool {
+	return ebiten.IsKeyPressed(g.keys[action])
+}
+
+// gamepadJustPressed reports whether the button was pressed this tick on any connected
+// gamepad with the standard layout. With no gamepad it is always false.
+func (g *Game) gamepadJustPressed(button ebiten.StandardGamepadButton) bool {
+	for _, id := range g.gamepadIDs {
+		if ebiten.IsStandardGamepadLayoutAvailable(id) && inpututil.IsStandardGamepadButtonJustPressed(id, button) {
+			return true
+		}
+	}
+	return false
+}
+
+// seekMusic moves the music by delta within the tune, clamped to its start and end,
+// and shows the time readout
+func (g *Game) seekMusic(delta time.Duration) {
+	if g.audioPlayer == nil {
+		return
+	}
+
+	target := seekTarget(g.audioPlayer.Position(), delta, g.musicDuration(), g.musicLoopStart())
+	if err := g.audioPlayer.SetPosition(target); err != nil {
+		log.Printf("Failed to seek music: %v", err)
+		return
+	}
+	g.seekReadout = seekReadoutTicks
+}
+
+// seekTarget returns the position delta away from pos, clamped to [0, duration].
+// A looping tune plays on past its duration from loopStart, so pos is first brought
+// back into the current loop. A duration of 0, for an unknown length, only clamps at
+// the start.
+func seekTarget(pos, delta, duration, loopStart time.Duration) time.Duration {
+	if duration <= 0 {
+		return max(0, pos+delta)
+	}
+	pos = time.Duration(loopOffset(int64(pos), int64(duration), int64(loopStart)))
+	return max(0, min(duration, pos+delta))
+}
+
+// musicDuration returns the length of one play of the tune, 0 when unknown
+func (g *Game) musicDuration() time.Duration {
+	if g.music == nil {
+		return 0
+	}
+	return time.Duration(g.music.Info().DurationMs) * time.Millisecond
+}
+
+// musicLoopStart returns where the tune starts again after its end
+func (g *Game) musicLoopStart() time.Duration {
+	if g.music == nil {
+		return 0
+	}
+	return time.Duration(g.music.Info().LoopMs) * time.Millisecond
+}
+
+// drawSeekReadout shows the music time and length centered above the progress bar
+func (g *Game) drawSeekReadout(screen *ebiten.Image) {
+	pos := g.audioPlayer.Position()
+	label := formatMusicTime(pos)
+	if duration := g.musicDuration(); duration > 0 {
+		pos = time.Duration(loopOffset(int64(pos), int64(duration), int64(g.musicLoopStart())))
+		label = formatMusicTime(pos) + " OF " + formatMusicTime(duration)
+	}
+
+	width := g.MeasureText([]rune(label), muteFontScale)
+	y := float64(screen.Bounds().Dy()) - progressBarHeight - fontHeight*muteFontScale - 8
+	g.drawText(screen, label, math.Round((float64(screen.Bounds().Dx())-width)/2), y, muteFontScale)
+}
+
+// formatMusicTime formats a music position as minutes and seconds, such as 1:05
+func formatMusicTime(d time.Duration) string {
+	seconds := int(d / time.Second)
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// setVolume changes the music volume, clamped to [0, 1]
+func (g *Game) setVolume(volume float64) {
+	g.volume = math.Max(0, math.Min(1, volume))
+	if g.audioPlayer != nil {
+		g.audioPlayer.SetVolume(g.volume)
+	}
+}
+
+// animationSteps returns how many fixed animation steps to run in this Update.
+// Real time is accumulated so the demo plays at the same speed whatever the TPS;
+// in deterministic mode every Update is exactly one step.
+func (g *Game) animationSteps() int {
+	if g.cfg.Deterministic {
+		return 1
+	}
+
+	now := time.Now()
+	if g.lastUpdate.IsZero() {
+		g.lastUpdate = now
+		g.accumulator = 0
+		return 1
+	}
+	g.accumulator += now.Sub(g.lastUpdate).Seconds()
+	g.lastUpdate = now
+
+	steps := int(g.accumulator / animationStep)
+	if steps > maxAnimationSteps {
+		// Drop the backlog after a stall instead of fast-forwarding through it
+		steps = maxAnimationSteps
+		g.accumulator = 0
+	} else {
+		g.accumulator -= float64(steps) * animationStep
+	}
+	return steps
+}
+
+// checkAudioError stops the music once the tune has failed, so the demo goes on silently
+func (g *Game) checkAudioError() {
+	if g.audioPlayer == nil {
+		return
+	}
+	if err := g.music.Err(); err != nil {
+		log.Printf("Music stopped: %v", err)
+		g.audioPlayer.Close()
+		g.audioPlayer = nil
+		g.music.Close()
+		g.music = nil
+	}
+}
+
+// updateMusicSync samples the music energy and beat for the audio-reactive effects.
+// Without music, or while it is paused, the effects read no energy and no beats.
+func (g *Game) updateMusicSync() {
+	g.beatFlash *= beatDecay
+	g.updateShake()
+	if g.music == nil || g.musicPaused {
+		g.musicEnergy = 0
+		return
+	}
+
+	g.musicEnergy = g.music.Energy()
+	if g.music.Beat() {
+		g.beatFlash = 1
+		g.shake = g.cfg.ShakeMagnitude
+	}
+}
+
+// musicLabel returns the music state shown in the corner, empty while it plays normally
+func (g *Game) musicLabel() string {
+	switch {
+	case g.musicPaused:
+		return "MUSIC PAUSED"
+	case g.muted:
+		return "MUTE"
+	}
+	return ""
+}
+
+// updateShake decays the beat shake and picks the next jitter of the final composite.
+// Once the shake has died down the composite is back exactly at the center.
+func (g *Game) updateShake() {
+	g.shake *= g.cfg.ShakeDecay
+	if g.shake < shakeSettle {
+		g.shake = 0
+		g.shakeX, g.shakeY = 0, 0
+		return
+	}
+	g.shakeX = (g.rng.Float64()*2 - 1) * g.shake
+	g.shakeY = (g.rng.Float64()*2 - 1) * g.shake
+}
+
+// Draw renders the game
+func (g *Game) Draw(screen *ebiten.Image) {
+	// In the background, show the frame kept on the way there instead of composing the
+	// held effects again, unless the window has been resized since
+	background := g.inBackground()
+	if background && g.holding && g.held.Bounds().Eq(screen.Bounds()) {
+		screen.DrawImage(g.held, nil)
+		return
+	}
+	g.holding = false
+
+	// Render the demo at its logical size
+	g.frame.Clear()
+	g.sequencer.Draw(g.frame)
+
+	// Music progress, only once the main demo is running
+	if g.showProgress && g.introComplete && g.music != nil {
+		g.drawProgressBar(g.frame)
+	}
+
+	// Music time for a moment after seeking
+	if g.seekReadout > 0 && g.audioPlayer != nil {
+		g.drawSeekReadout(g.frame)
+	}
+
+	// Music state in the corner, the pause taking precedence since nothing plays
+	if label := g.musicLabel(); label != "" {
+		width := g.MeasureText([]rune(label), muteFontScale)
+		g.drawText(g.frame, label, screenWidth-width-8, 8, muteFontScale)
+	}
+
+	// Debug overlay
+	g.recordFrameTime()
+	if g.showDebug {
+		g.drawDebugOverlay(g.frame)
+	}
+
+	// Scale to the window keeping the aspect ratio, with black bars on the sides
+	g.viewport = letterbox(screen.Bounds().Dx(), screen.Bounds().Dy())
+	screen.Fill(color.Black)
+	g.frameOp.GeoM.Reset()
+	g.frameOp.GeoM.Scale(float64(g.viewport.Dx())/screenWidth, float64(g.viewport.Dy())/screenHeight)
+	g.frameOp.GeoM.Translate(float64(g.viewport.Min.X), float64(g.viewport.Min.Y))
+	g.frameOp.ColorScale.Reset()
+	brightness := float32(g.cfg.Brightness)
+	if g.quitTicks > 0 {
+		brightness *= float32(g.quitTicks) / quitFadeTicks
+	}
+	g.frameOp.ColorScale.Scale(brightness, brightness, brightness, 1)
+	if g.gammaShader != nil {
+		g.drawGammaCorrected(screen)
+	} else {
+		screen.DrawImage(g.frame, g.frameOp)
+	}
+	if g.phosphorMask != nil {
+		g.drawPhosphorMask(screen, g.viewport)
+	}
+
+	if g.wantScreenshot {
+		g.wantScreenshot = false
+		g.saveScreenshot(screen)
+	}
+
+	// Keep the frame to show while in the background
+	if background {
+		if g.held == nil || !g.held.Bounds().Eq(screen.Bounds()) {
+			if g.held != nil {
+				g.held.Dispose()
+			}
+			g.held = ebiten.NewImage(screen.Bounds().Dx(), screen.Bounds().Dy())
+		}
+		g.held.Clear()
+		g.held.DrawImage(screen, nil)
+		g.holding = true
+	}
+}
+
+// drawProgressBar draws how far the music has played as a thin bar along the bottom
+func (g *Game) drawProgressBar(screen *ebiten.Image) {
+	w := float32(screen.Bounds().Dx())
+	y := float32(screen.Bounds().Dy()) - progressBarHeight
+
+	vector.DrawFilledRect(screen, 0, y, w, progressBarHeight, color.RGBA{40, 40, 40, 160}, false)
+	vector.DrawFilledRect(screen, 0, y, w*float32(g.music.Progress()), progressBarHeight, color.RGBA{255, 200, 0, 255}, false)
+}
+
+// letterbox returns the largest rectangle with the demo's aspect ratio centered in a w×h screen
+func letterbox(w, h int) image.Rectangle {
+	scale := math.Min(float64(w)/screenWidth, float64(h)/screenHeight)
+	vw := int(math.Round(screenWidth * scale))
+	vh := int(math.Round(screenHeight * scale))
+	x := (w - vw) / 2
+	y := (h - vh) / 2
+	return image.Rect(x, y, x+vw, y+vh)
+}
+
+// drawIntro draws the intro scroll and the music credits
+func (g *Game) drawIntro(screen *ebiten.Image) {
+	screen.Fill(color.Black)
+
+	// Draw the intro scroll centered, and the bottom ticker
+	g.drawIntroBand(screen, g.intro, screenHeight/2-int(fontHeight*introFontScale)/2)
+	if g.introTicker != nil {
+		g.drawIntroBand(screen, g.introTicker, g.cfg.IntroTickerY)
+	}
+
+	// Draw music credits
+	g.drawCredits(screen)
+}
+
+// drawIntroBand draws an intro ticker across the screen at y, through the CRT shader when enabled
+func (g *Game) drawIntroBand(screen *ebiten.Image, t *Ticker, y int) {
+	g.drawTicker(t)
+	if g.crtShader != nil && g.crtEnabled {
+		g.drawRectOp.Images[0] = t.canvas
+		g.drawRectOp.GeoM.Reset()
+		g.drawRectOp.GeoM.Translate(0, float64(y))
+		g.drawRectOp.ColorScale.Reset()
+		g.drawRectOp.Uniforms = g.crtUniforms(g.shaderTime)
+
+		screen.DrawRectShader(t.canvas.Bounds().Dx(), t.canvas.Bounds().Dy(), g.crtShader, g.drawRectOp)
+	} else {
+		// Fallback without shader - draw at fixed position
+		g.drawOp.GeoM.Reset()
+		g.drawOp.ColorScale.Reset()
+		g.drawOp.GeoM.Translate(0, float64(y))
+		screen.DrawImage(t.canvas, g.drawOp)
+	}
+}
+
+// drawDemo draws the main demo composited at the center of the screen
+func (g *Game) drawDemo(screen *ebiten.Image) {
+	screen.Fill(color.Black)
+	g.drawMainDemo()
+
+	// Bloom the bright areas before compositing
+	canvas := g.stCanvas
+	if g.bloomShader != nil && g.bloomEnabled {
+		g.applyBloom()
+		canvas = g.bloomCanvas
+	}
+
+	// Final composite - center the canvas
+	if g.crtShader != nil && g.crtDemo {
+		g.drawRectOp.Images[0] = canvas
+		g.drawRectOp.GeoM.Reset()
+		g.drawRectOp.GeoM.Translate(64+g.shakeX, 70+g.shakeY)
+		g.drawRectOp.ColorScale.Reset()
+		g.drawRectOp.Uniforms = g.crtUniforms(g.shaderTime)
+
+		screen.DrawRectShader(stCanvasWidth, stCanvasHeight, g.crtShader, g.drawRectOp)
+	} else {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(64+g.shakeX, 70+g.shakeY)
+		screen.DrawImage(canvas, op)
+	}
+}
+
+// recordFrameTime stores the time elapsed since the previous Draw call
+func (g *Game) recordFrameTime() {
+	now := time.Now()
+	if !g.lastFrame.IsZero() {
+		g.frameTimes[g.frameIndex] = float64(now.Sub(g.lastFrame).Microseconds()) / 1000
+		g.frameIndex = (g.frameIndex + 1) % debugFrameSamples
+		if g.frameCount < debugFrameSamples {
+			g.frameCount++
+		}
+	}
+	g.lastFrame = now
+}
+
+// drawDebugOverlay draws FPS, TPS and the average frame time in the top left corner
+func (g *Game) drawDebugOverlay(screen *ebiten.Image) {
+	average := 0.0
+	for i := 0; i < g.frameCount; i++ {
+		average += g.frameTimes[i]
+	}
+	if g.frameCount > 0 {
+		average /= float64(g.frameCount)
+	}
+
+	lineHeight := fontHeight*debugFontScale + 2
+	g.drawText(screen, fmt.Sprintf("FPS %.1f", ebiten.ActualFPS()), 8, 8, debugFontScale)
+	g.drawText(screen, fmt.Sprintf("TPS %.1f", ebiten.ActualTPS()), 8, 8+lineHeight, debugFontScale)
+	g.drawText(screen, fmt.Sprintf("FRAME %.2f MS", average), 8, 8+2*lineHeight, debugFontScale)
+	g.drawFrameGraph(screen, 8, 12+3*lineHeight)
+}
+
+// drawFrameGraph plots the recent frame times, oldest first, against a line at the 60 FPS budget
+func (g *Game) drawFrameGraph(screen *ebiten.Image, x, y float64) {
+	budget := y + debugGraphHeight*(1-1000.0/60/debugGraphMaxMs)
+	drawLine(screen, x, budget, x+debugGraphWidth, budget, color.RGBA{0, 160, 0, 255}, 1)
+
+	var prevX, prevY float64
+	for i := 0; i < g.frameCount; i++ {
+		ms := g.frameTimes[(g.frameIndex-g.frameCount+i+debugFrameSamples)%debugFrameSamples]
+		px := x + float64(i)*debugGraphWidth/(debugFrameSamples-1)
+		py := y + debugGraphHeight*(1-math.Min(ms/debugGraphMaxMs, 1))
+		if i > 0 {
+			drawLine(screen, prevX, prevY, px, py, color.RGBA{255, 200, 0, 255}, 1)
+		}
+		prevX, prevY = px, py
+	}
+}
+
+// drawLine draws an antialiased line of the given width. The end points can fall between
+// pixels, so moving lines glide instead of snapping to the pixel grid.
+func drawLine(dst *ebiten.Image, x0, y0, x1, y1 float64, c color.Color, width float32) {
+	vector.StrokeLine(dst, float32(x0), float32(y0), float32(x1), float32(y1), width, c, true)
+}
+
+// saveScreenshot writes the screen contents to a timestamped PNG in the working directory
+func (g *Game) saveScreenshot(screen *ebiten.Image) {
+	bounds := screen.Bounds()
+	img := image.NewRGBA(bounds)
+	screen.ReadPixels(img.Pix)
+
+	path := fmt.Sprintf("teamg1-%s.png", time.Now().Format("20060102-150405.000"))
+	if err := writePNG(path, img); err != nil {
+		log.Printf("Failed to save screenshot: %v", err)
+		return
+	}
+	log.Printf("Screenshot saved to %s", path)
+}
+
+// writePNG encodes img as a PNG file at path
+func writePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	return file.Close()
+}
+
+// RunFrames calls Update and Draw n times, drawing into dst, to profile the effects
+// without the Ebiten run loop. Create the game in deterministic mode so that each Update
+// is exactly one animation step, whatever the time the frames take.
+// Outside RunGame, Ebiten queues the draw commands without running them on the GPU, so a
+// benchmark measures the CPU side of a frame: the plasma and fire pixels, the cube transform
+// and raster, the scroll wave and the command building, but not the GPU work.
+func (g *Game) RunFrames(n int, dst *ebiten.Image) error {
+	for i := 0; i < n; i++ {
+		if err := g.Update(); err != nil {
+			return fmt.Errorf("failed to update frame %d: %w", i, err)
+		}
+		g.Draw(dst)
+	}
+	return nil
+}
+
+// RenderFrames renders a frame of the demo offscreen, for golden-image tests.
+// It creates a silent Game, calls Update the given number of times and draws the result
+// into a screenWidth×screenHeight image. It runs in deterministic mode, where every Update
+// is one fixed 1/60 s step, and the music sync is disabled, so the same config and update count always give the same frame.
+// Ebiten only runs GPU commands while a game is running, so call it from inside RunGame.
+func RenderFrames(cfg Config, updates int) (*image.RGBA, error) {
+	cfg.NoAudio = true
+	cfg.Deterministic = true
+	if cfg.Seed == 0 {
+		cfg.Seed = 1 // A time-based seed would change the frame from run to run
+	}
+	cfg.ShaderPath = ""
+	g := NewGame(cfg)
+	defer g.Cleanup()
+
+	for i := 0; i < updates; i++ {
+		if err := g.Update(); err != nil {
+			return nil, fmt.Errorf("failed to update frame %d: %w", i, err)
+		}
+	}
+
+	screen := ebiten.NewImage(screenWidth, screenHeight)
+	defer screen.Dispose()
+	g.Draw(screen)
+
+	img := image.NewRGBA(screen.Bounds())
+	screen.ReadPixels(img.Pix)
+	return img, nil
+}
+
+// Layout returns the window size, so Draw can letterbox the demo into it
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return outsideWidth, outsideHeight
+}
+
+// Cleanup releases resources
+func (g *Game) Cleanup() {
+	if g.audioPlayer != nil {
+		g.audioPlayer.Close()
+	}
+	if g.music != nil {
+		g.music.Close()
+	}
+	if g.crtShader != nil {
+		g.crtShader.Dispose()
+	}
+	if g.plasmaField.shader != nil {
+		g.plasmaField.shader.Dispose()
+	}
+	if g.bloomShader != nil {
+		g.bloomShader.Dispose()
+	}
+	if g.gammaShader != nil {
+		g.gammaShader.Dispose()
+	}
+}
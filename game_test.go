@@ -0,0 +1,304 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestNewGame(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NoAudio = true
+	cfg.Seed = 1
+
+	g := NewGame(cfg)
+	defer g.Cleanup()
+
+	if g.sequencer == nil {
+		t.Fatal("NewGame left the scene sequencer unset")
+	}
+	if len(g.letterData) == 0 {
+		t.Error("NewGame loaded no font glyphs")
+	}
+	if len(g.cubeFaces) == 0 {
+		t.Error("NewGame loaded no cube faces")
+	}
+	if g.music != nil || g.audioPlayer != nil {
+		t.Error("NewGame started the music with audio disabled")
+	}
+}
+
+// BenchmarkRunFrames times the main demo frames. Ebiten doesn't run the queued draw
+// commands outside RunGame, so this covers the CPU work of the effects only.
+// In steady state the demo's own code makes 14 allocations a frame, none of them draw
+// options: the cube face subdivision and the depth sorts of the cube and logo spiral.
+// The plasma shader's uniforms add a few more. Ebiten's draw calls add their own on top.
+func BenchmarkRunFrames(b *testing.B) {
+	for _, background := range []string{"plasma", "fire", "tunnel"} {
+		b.Run(background, func(b *testing.B) {
+			cfg := DefaultConfig()
+			cfg.NoAudio = true
+			cfg.Deterministic = true
+			cfg.Seed = 1
+			cfg.StartScene = sceneDemo
+			cfg.Background = background
+
+			g := NewGame(cfg)
+			defer g.Cleanup()
+			screen := ebiten.NewImage(screenWidth, screenHeight)
+			defer screen.Dispose()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			if err := g.RunFrames(b.N, screen); err != nil {
+				b.Fatal(err)
+			}
+		})
+	}
+}
+
+// TestRunFramesAdvance checks that RunFrames moves the animation on, although Ebiten
+// reports the window as unfocused outside RunGame
+func TestRunFramesAdvance(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NoAudio = true
+	cfg.Deterministic = true
+	cfg.Seed = 1
+
+	g := NewGame(cfg)
+	defer g.Cleanup()
+	screen := ebiten.NewImage(screenWidth, screenHeight)
+	defer screen.Dispose()
+
+	const frames = 10
+	if err := g.RunFrames(frames, screen); err != nil {
+		t.Fatal(err)
+	}
+	if want := frames * animationStep; math.Abs(g.shaderTime-want) > 1e-9 {
+		t.Errorf("shader time %v after %d frames, want %v", g.shaderTime, frames, want)
+	}
+}
+
+// TestBackgroundHold checks that in the background, as outside RunGame, Draw composes
+// the frame once and then shows the kept one
+func TestBackgroundHold(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NoAudio = true
+	cfg.Seed = 1
+
+	g := NewGame(cfg)
+	defer g.Cleanup()
+	screen := ebiten.NewImage(screenWidth, screenHeight)
+	defer screen.Dispose()
+
+	for i := 0; i < 3; i++ {
+		g.Draw(screen)
+	}
+	if !g.holding {
+		t.Error("Draw kept no frame in the background")
+	}
+	if g.frameCount != 0 {
+		t.Errorf("Draw composed %d more frames in the background, want none", g.frameCount)
+	}
+}
+
+// TestIntroFeed checks that the intro feeds its letters in step with the scroll at any
+// speed: after each step the current letter has gone past the feed position by at most
+// one step, so the next letter is fed in time and letters don't pile up on each other.
+// The first letter starts one pixel past the feed position.
+func TestIntroFeed(t *testing.T) {
+	for _, speed := range []int{1, 6, 24, 100, 300} {
+		cfg := DefaultConfig()
+		cfg.NoAudio = true
+		cfg.Seed = 1
+		cfg.IntroScrollSpeed = speed
+
+		g := NewGame(cfg)
+		for steps := 0; !g.introComplete; steps++ {
+			if steps > len(g.intro.runes)*stCanvasWidth {
+				t.Fatalf("speed %d: intro not complete after %d steps", speed, steps)
+			}
+			g.animIntro()
+			if g.introComplete {
+				break
+			}
+			if g.intro.x < -speed-1 {
+				t.Fatalf("speed %d: letter %d at offset %d, more than one step past the feed position", speed, g.intro.letter, g.intro.x)
+			}
+		}
+		g.Cleanup()
+	}
+}
+
+// TestPlasmaFrame renders a plasma frame on the CPU path, serially and in bands, and
+// checks that every pixel of the uploaded frame is written and both agree
+func TestPlasmaFrame(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NoAudio = true
+	cfg.Seed = 1
+
+	g := NewGame(cfg)
+	defer g.Cleanup()
+	g.plasmaField.shader = nil
+
+	frame := func(workers int) []byte {
+		g.plasmaField.time = 0
+		g.plasmaField.workers = workers
+		g.updatePlasma()
+		return append([]byte(nil), g.plasmaField.pixels...)
+	}
+	serial, banded := frame(1), frame(4)
+
+	if len(serial) != 4*g.plasmaField.width*g.plasmaField.height {
+		t.Fatalf("frame has %d bytes, want %d", len(serial), 4*g.plasmaField.width*g.plasmaField.height)
+	}
+	for i := 0; i < len(serial); i += 4 {
+		if serial[i+3] != 255 {
+			t.Fatalf("pixel %d left transparent", i/4)
+		}
+	}
+	for i := range serial {
+		if serial[i] != banded[i] {
+			t.Fatalf("pixel %d differs between serial and banded rendering", i/4)
+		}
+	}
+}
+
+// BenchmarkPlasmaCPU times a CPU plasma frame uploaded with a single WritePixels, and the
+// same frame uploaded with one Set call per pixel as it used to be. Both render serially
+// so only the upload differs.
+func BenchmarkPlasmaCPU(b *testing.B) {
+	cfg := DefaultConfig()
+	cfg.NoAudio = true
+	cfg.Seed = 1
+
+	g := NewGame(cfg)
+	defer g.Cleanup()
+	p := g.plasmaField
+	p.shader = nil
+	p.workers = 1
+
+	b.Run("WritePixels", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			g.updatePlasma()
+		}
+	})
+	b.Run("Set", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			p.renderRows(0, p.height)
+			for y := 0; y < p.height; y++ {
+				for x := 0; x < p.width; x++ {
+					p.buffer.Set(x, y, p.lut[p.indices[y*p.width+x]])
+				}
+			}
+		}
+	})
+}
+
+// BenchmarkPlasmaWorkers times the CPU plasma rendered serially and, on a machine with
+// several CPUs, in one band per CPU
+func BenchmarkPlasmaWorkers(b *testing.B) {
+	cfg := DefaultConfig()
+	cfg.NoAudio = true
+	cfg.Seed = 1
+
+	g := NewGame(cfg)
+	defer g.Cleanup()
+	g.plasmaField.shader = nil
+
+	counts := []int{1}
+	if n := runtime.NumCPU(); n > 1 {
+		counts = append(counts, n)
+	}
+	for _, workers := range counts {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			g.plasmaField.workers = workers
+			for i := 0; i < b.N; i++ {
+				g.updatePlasma()
+			}
+		})
+	}
+}
+
+// TestMusicPauseEnergy checks that the audio-reactive effects read no energy or beats
+// while only the music is paused, and pick them up again once it resumes
+func TestMusicPauseEnergy(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NoAudio = true
+	cfg.Seed = 1
+
+	g := NewGame(cfg)
+	defer g.Cleanup()
+	tone := NewToneGenerator(defaultSampleRate)
+	g.music = tone
+
+	// Start a pulse, leaving the tone loud with a beat pending
+	if _, err := tone.Read(make([]byte, 4*64)); err != nil {
+		t.Fatal(err)
+	}
+
+	g.musicPaused = true
+	g.updateMusicSync()
+	if g.musicEnergy != 0 || g.beatFlash != 0 {
+		t.Errorf("music paused: energy %v, beat flash %v, want 0", g.musicEnergy, g.beatFlash)
+	}
+
+	g.musicPaused = false
+	g.updateMusicSync()
+	if g.musicEnergy == 0 || g.beatFlash != 1 {
+		t.Errorf("music resumed: energy %v, beat flash %v, want the pulse", g.musicEnergy, g.beatFlash)
+	}
+}
+
+// TestAttractTuneEnd checks that attract mode without a duration loops back to the intro
+// once the tune has played once
+func TestAttractTuneEnd(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NoAudio = true
+	cfg.Seed = 1
+	cfg.Attract = true
+
+	g := NewGame(cfg)
+	defer g.Cleanup()
+	tune, err := NewYMPlayer(ym3Tune(50), defaultSampleRate, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.useTune(tune)
+
+	if g.attractDue() {
+		t.Fatal("attract mode looped back before the tune ended")
+	}
+	readToEnd(t, tune, 4*tune.totalSamples)
+	if !g.attractDue() {
+		t.Error("attract mode didn't loop back at the end of the tune")
+	}
+}
+
+// TestSeekTarget checks that seeks stay within the tune, including on later loops
+func TestSeekTarget(t *testing.T) {
+	const s = time.Second
+	tests := []struct {
+		pos, delta, duration, loopStart, want time.Duration
+	}{
+		{10 * s, 5 * s, 60 * s, 0, 15 * s},
+		{10 * s, -5 * s, 60 * s, 0, 5 * s},
+		{3 * s, -5 * s, 60 * s, 0, 0},
+		{58 * s, 5 * s, 60 * s, 0, 60 * s},
+		{130 * s, 5 * s, 60 * s, 0, 15 * s}, // Third loop
+		{122 * s, -5 * s, 60 * s, 0, 0},
+		{130 * s, 5 * s, 60 * s, 20 * s, 55 * s}, // Third loop, from 20s
+		{60 * s, 0, 60 * s, 20 * s, 20 * s},
+		{100 * s, 5 * s, 0, 0, 105 * s}, // Unknown length
+		{2 * s, -5 * s, 0, 0, 0},
+	}
+	for _, test := range tests {
+		if got := seekTarget(test.pos, test.delta, test.duration, test.loopStart); got != test.want {
+			t.Errorf("seekTarget(%v, %v, %v, %v) = %v, want %v", test.pos, test.delta, test.duration, test.loopStart, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"image"
+	"math"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// Logo spiral parameters
+	defaultLogoCount = 12    // Default number of GAMEONE logos in the spiral
+	logoFocalLength  = 400.0 // Perspective focal length of the spiral
+	logoDepthWave    = 40.0  // Extra per-logo depth oscillation
+	logoHuePeriod    = 4.0   // Spiral time units per full rainbow cycle
+
+	// Logo distortion parameters, adjustable at runtime
+	defaultLogoAmplitude = 0.15 // Much smaller line distortion than the raw sine table
+	defaultLogoSpeed     = 2.0  // Moderate speed
+	logoAmplitudeStep    = 0.05
+	logoAmplitudeMax     = 1.0
+	logoSpeedStep        = 0.5
+	logoSpeedMax         = 10.0
+	logoRippleHeight     = 4.0 // Vertical ripple in pixels at the default amplitude
+
+	// Top of the undistorted TEAMG1 logo on the main canvas
+	logoTopY = 60.0
+
+	// Copper bar parameters
+	copperBarHeight = 24  // Height of one bar in pixels
+	copperAmplitude = 70  // Vertical travel of the bars around the logo center
+	copperPhase     = 0.5 // Phase difference between neighbouring bars in radians
+)
+
+// LogoDistortion handles the logo distortion effect
+type LogoDistortion struct {
+	distSin     []float64
+	vertDistSin []float64 // Per-scanline vertical offsets for the flag-wave ripple
+	vertical    bool      // Apply vertDistSin on top of the horizontal distortion
+	distCount   float64
+	distCanvas  *ebiten.Image
+	amplitude   float64 // Scale applied to the sine table per scanline
+	speed       float64 // Sine table entries advanced per frame
+}
+
+// initLogoDistortion initializes the logo distortion effect
+func (g *Game) initLogoDistortion() {
+	g.logoDistort = &LogoDistortion{
+		distCanvas: ebiten.NewImage(256, 122),
+		distCount:  0,
+		amplitude:  g.cfg.LogoAmplitude,
+		speed:      g.cfg.LogoSpeed,
+	}
+
+	// Initialize distortion sine table with more subtle values
+	g.logoDistort.distSin = make([]float64, 0)
+
+	// Gentle sine waves for line distortion
+	for i := 0; i < 200; i++ {
+		g.logoDistort.distSin = append(g.logoDistort.distSin, 50*math.Sin(float64(i)*0.05))
+	}
+
+	// Some variation
+	for i := 0; i < 100; i++ {
+		g.logoDistort.distSin = append(g.logoDistort.distSin, 30*math.Sin(float64(i)*0.1)+20*math.Cos(float64(i)*0.07))
+	}
+
+	// Different pattern
+	for i := 0; i < 150; i++ {
+		g.logoDistort.distSin = append(g.logoDistort.distSin, 40*math.Sin(float64(i)*0.03))
+	}
+
+	// Calm section
+	for i := 0; i < 100; i++ {
+		g.logoDistort.distSin = append(g.logoDistort.distSin, 20*math.Sin(float64(i)*0.08))
+	}
+
+	// Near zero
+	for i := 0; i < 50; i++ {
+		g.logoDistort.distSin = append(g.logoDistort.distSin, 10*math.Sin(float64(i)*0.1))
+	}
+
+	// One full period of vertical ripple
+	g.logoDistort.vertDistSin = make([]float64, 256)
+	for i := range g.logoDistort.vertDistSin {
+		g.logoDistort.vertDistSin[i] = logoRippleHeight * math.Sin(float64(i)*2*math.Pi/256)
+	}
+}
+
+// initLogoSpiral initializes positions for the GAMEONE logo spiral
+func (g *Game) initLogoSpiral() {
+	if g.logoCount < 1 {
+		g.logoCount = 1
+	}
+
+	g.logoPositions = make([]Vector3, g.logoCount)
+	for i := 0; i < g.logoCount; i++ {
+		angle := float64(i) * math.Pi * 2 / float64(g.logoCount)
+		radius := 150.0
+		g.logoPositions[i] = Vector3{
+			X: math.Cos(angle) * radius,
+			Y: math.Sin(angle) * radius,
+			Z: 0,
+		}
+	}
+}
+
+// adjust changes the distortion amplitude and speed by the given steps, within bounds
+func (d *LogoDistortion) adjust(amplitudeStep, speedStep float64) {
+	d.amplitude = math.Max(0, math.Min(logoAmplitudeMax, d.amplitude+amplitudeStep))
+	d.speed = math.Max(0, math.Min(logoSpeedMax, d.speed+speedStep))
+}
+
+// drawLogoSpiral draws the GAMEONE logos in a spiral pattern
+func (g *Game) drawLogoSpiral() {
+	g.logoCanvas.Clear()
+
+	// Place logos in 3D
+	type spiralLogo struct {
+		x, y, z float64
+		index   int
+	}
+
+	logos := make([]spiralLogo, len(g.logoPositions))
+	for i, pos := range g.logoPositions {
+		// Rotate position
+		radius := math.Sqrt(pos.X*pos.X + pos.Y*pos.Y)
+		angle := g.logoTime + float64(i)*math.Pi*2/float64(len(g.logoPositions))
+		x := math.Cos(angle) * radius
+		y := math.Sin(angle) * radius
+
+		// Depth swings around the ring as it slowly tilts back and forth
+		z := math.Sin(angle) * radius * math.Sin(g.logoTime*0.5)
+
+		// Add wave motion
+		x += math.Sin(g.logoTime*2+float64(i)) * 20
+		y += math.Cos(g.logoTime*2+float64(i)) * 20
+		z += math.Sin(g.logoTime*1.5+float64(i)*0.5) * logoDepthWave
+
+		logos[i] = spiralLogo{x: x, y: y, z: z, index: i}
+	}
+
+	// Draw far logos first so nearer ones overlap them
+	sort.Slice(logos, func(i, j int) bool {
+		return logos[i].z > logos[j].z
+	})
+
+	for _, logo := range logos {
+		// Nearer logos are bigger and brighter
+		depth := logoFocalLength / (logoFocalLength + logo.z)
+		scale := 0.6 * depth
+		scale *= 1 + energyCoupling*g.musicEnergy
+		brightness := float32(math.Max(0.3, math.Min(1, depth*depth)))
+
+		// Draw logo
+		op := g.drawOp
+		op.GeoM.Reset()
+		op.ColorScale.Reset()
+		op.GeoM.Translate(-float64(g.gameOneLogo.Bounds().Dx())/2, -float64(g.gameOneLogo.Bounds().Dy())/2)
+		op.GeoM.Scale(scale, scale)
+		op.GeoM.Translate(logo.x*depth+float64(g.logoCanvas.Bounds().Dx())/2, logo.y*depth+float64(g.logoCanvas.Bounds().Dy())/2)
+		op.ColorScale.Scale(brightness, brightness, brightness, 1)
+
+		// Rainbow around the ring, looping once per hue period
+		if g.logoHueCycle {
+			hue := g.logoTime/logoHuePeriod + float64(logo.index)/float64(len(logos))
+			r, gr, b := hueToRGB(hue)
+			op.ColorScale.Scale(float32(r), float32(gr), float32(b), 1)
+		}
+
+		g.logoCanvas.DrawImage(g.gameOneLogo, op)
+	}
+}
+
+// newCopperBarImage returns a 1×copperBarHeight strip of the color, dark at the edges
+// and brightest in the middle like a rounded metal bar
+func newCopperBarImage(c [3]uint8) *ebiten.Image {
+	pixels := make([]byte, 4*copperBarHeight)
+	for y := 0; y < copperBarHeight; y++ {
+		shade := math.Sin(math.Pi * (float64(y) + 0.5) / copperBarHeight)
+		for i := 0; i < 3; i++ {
+			pixels[4*y+i] = uint8(float64(c[i]) * shade)
+		}
+		pixels[4*y+3] = 255
+	}
+
+	img := ebiten.NewImage(1, copperBarHeight)
+	img.WritePixels(pixels)
+	return img
+}
+
+// drawCopperBars draws full-width bars swinging on a sine around the logo center,
+// each one a little behind the previous
+func (g *Game) drawCopperBars() {
+	centerY := logoTopY + float64(g.teamG1Logo.Bounds().Dy())/2
+
+	// Back to front, so the first bar passes over the others
+	for i := g.cfg.CopperCount - 1; i >= 0; i-- {
+		y := centerY + math.Sin(g.demoTime*g.cfg.CopperSpeed-float64(i)*copperPhase)*copperAmplitude
+
+		op := g.drawOp
+		op.GeoM.Reset()
+		op.ColorScale.Reset()
+		op.GeoM.Scale(float64(g.stCanvas.Bounds().Dx()), 1)
+		op.GeoM.Translate(0, y-copperBarHeight/2)
+		g.stCanvas.DrawImage(g.copperImages[i%len(g.copperImages)], op)
+	}
+}
+
+// drawDistortedLogo draws the TEAMG1 logo with sine wave distortion (like JS version)
+func (g *Game) drawDistortedLogo() {
+	// Base position - this will move across the screen
+	baseX := float64(g.stCanvas.Bounds().Dx()) / 2
+	logoY := logoTopY
+
+	// Calculate overall logo movement (can move across full screen width)
+	overallMovement := math.Sin(g.logoDistort.distCount*0.01) * float64(g.stCanvas.Bounds().Dx()/2)
+
+	// Apply distortion per scanline with reduced amplitude
+	for y := 0; y < g.teamG1Logo.Bounds().Dy(); y++ {
+		// Get distortion value for this line - reduced amplitude
+		idx := (int(g.logoDistort.distCount) + y*2) % len(g.logoDistort.distSin)
+		lineDistortion := g.logoDistort.distSin[idx] * g.logoDistort.amplitude
+
+		// Vertical ripple, shared by the wrapped copies so they line up
+		lineY := logoY + float64(y)
+		if g.logoDistort.vertical {
+			vidx := (int(g.logoDistort.distCount) + y*3) % len(g.logoDistort.vertDistSin)
+			lineY += g.logoDistort.vertDistSin[vidx] * g.logoDistort.amplitude / defaultLogoAmplitude
+		}
+
+		// Calculate final X position
+		finalX := baseX + overallMovement + lineDistortion - float64(g.teamG1Logo.Bounds().Dx())/2
+
+		// Wrap around screen edges
+		screenWidth := float64(g.stCanvas.Bounds().Dx())
+		logoWidth := float64(g.teamG1Logo.Bounds().Dx())
+
+		// Draw this line of the logo
+		srcRect := image.Rect(0, y, g.teamG1Logo.Bounds().Dx(), y+1)
+
+		// Main position
+		if finalX > -logoWidth && finalX < screenWidth {
+			op := g.drawOp
+			op.GeoM.Reset()
+			op.ColorScale.Reset()
+			op.GeoM.Translate(finalX, lineY)
+			g.stCanvas.DrawImage(g.teamG1Logo.SubImage(srcRect).(*ebiten.Image), op)
+		}
+
+		// Draw wrapped portion if needed
+		if finalX < 0 {
+			// Logo is partially off left, draw wrapped portion on right
+			wrapX := screenWidth + finalX
+			op := g.drawOp
+			op.GeoM.Reset()
+			op.ColorScale.Reset()
+			op.GeoM.Translate(wrapX, lineY)
+			g.stCanvas.DrawImage(g.teamG1Logo.SubImage(srcRect).(*ebiten.Image), op)
+		} else if finalX+logoWidth > screenWidth {
+			// Logo is partially off right, draw wrapped portion on left
+			wrapX := finalX - screenWidth
+			op := g.drawOp
+			op.GeoM.Reset()
+			op.ColorScale.Reset()
+			op.GeoM.Translate(wrapX, lineY)
+			g.stCanvas.DrawImage(g.teamG1Logo.SubImage(srcRect).(*ebiten.Image), op)
+		}
+	}
+}
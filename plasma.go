@@ -0,0 +1,236 @@
+package main
+
+import (
+	"image/color"
+	"math"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// Palette cycling speed in palette entries per frame
+	paletteCycleSpeed = 1.5
+
+	// Plasma buffers smaller than this many pixels are rendered serially
+	plasmaParallelMin = 16384
+)
+
+// PlasmaPalette selects the color mapping of the plasma
+type PlasmaPalette int
+
+const (
+	PaletteClassic PlasmaPalette = iota
+	PaletteFire
+	PaletteIce
+	PaletteRainbow
+	PaletteGrayscale
+	paletteCount
+)
+
+// PlasmaField represents the plasma effect background
+type PlasmaField struct {
+	time    float64
+	width   int
+	height  int
+	detail  float64 // Buffer pixels per unit of the pattern: 1 at half resolution, 2 at full
+	buffer  *ebiten.Image
+	pixels  []byte // RGBA pixels for the CPU path, uploaded with WritePixels
+	workers int    // Goroutines sharing the CPU path
+	shader  *ebiten.Shader
+	op      *ebiten.DrawRectShaderOptions
+
+	// Color lookup
+	Palette    PlasmaPalette
+	lut        [256]color.RGBA
+	paletteImg *ebiten.Image // Palette in the first row, sized like the buffer for the shader
+	indices    []uint8       // Palette index per pixel for the CPU path
+
+	// Palette cycling
+	cycling      bool
+	cycleOffset  float64
+	patternReady bool
+}
+
+// Plasma shader computing the same four sine waves as the CPU path, one pixel per fragment
+const plasmaShaderSrc = `
+//kage:unit pixels
+
+package main
+
+var Time float
+var Offset float
+var Detail float
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	// Integer pixel coordinates in pattern units, matching the CPU loop
+	var pos vec2
+	pos = floor(dstPos.xy - imageDstOrigin()) / Detail
+	
+	// Multiple sine waves for complex patterns
+	var v1 float
+	var v2 float
+	var v3 float
+	var v4 float
+	v1 = sin(pos.x * 0.02 + Time)
+	v2 = sin(pos.y * 0.03 + Time * 1.5)
+	v3 = sin(sqrt(pos.x * pos.x + pos.y * pos.y) * 0.01 + Time * 0.5)
+	v4 = sin((pos.x * 0.01 + pos.y * 0.01) + Time * 2.0)
+	
+	var v float
+	v = (v1 + v2 + v3 + v4) / 4.0
+	
+	// Look up the color in the palette stored in the first row of the source image
+	var idx float
+	idx = clamp(floor((v + 1.0) * 127.5), 0.0, 255.0)
+	idx = mod(idx + Offset, 256.0)
+	
+	return imageSrc0At(imageSrc0Origin() + vec2(idx + 0.5, 0.5))
+}
+`
+
+// updatePlasma updates the plasma effect
+func (g *Game) updatePlasma() {
+	// Palette cycling rotates the colors of a frozen pattern instead of moving it
+	if g.plasmaField.cycling {
+		g.plasmaField.cycleOffset = math.Mod(g.plasmaField.cycleOffset+paletteCycleSpeed*(1+energyCoupling*g.musicEnergy), 256)
+	} else {
+		g.plasmaField.time += g.cfg.PlasmaSpeed * (1 + energyCoupling*g.musicEnergy)
+	}
+
+	// Render on the GPU when the shader is available
+	if g.plasmaField.shader != nil {
+		g.plasmaField.op.Images[0] = g.plasmaField.paletteImg
+		g.plasmaField.op.Uniforms = map[string]interface{}{
+			"Time":   float32(g.plasmaField.time),
+			"Offset": float32(math.Floor(g.plasmaField.cycleOffset)),
+			"Detail": float32(g.plasmaField.detail),
+		}
+		g.plasmaField.buffer.DrawRectShader(g.plasmaField.width, g.plasmaField.height, g.plasmaField.shader, g.plasmaField.op)
+		return
+	}
+
+	// Generate plasma pattern in horizontal bands, one per worker.
+	// While cycling, the pattern is computed once and only the palette moves.
+	if !g.plasmaField.cycling || !g.plasmaField.patternReady {
+		workers := g.plasmaField.workers
+		if g.plasmaField.width*g.plasmaField.height < plasmaParallelMin || workers < 2 {
+			g.plasmaField.renderRows(0, g.plasmaField.height)
+		} else {
+			var wg sync.WaitGroup
+			band := (g.plasmaField.height + workers - 1) / workers
+			for y0 := 0; y0 < g.plasmaField.height; y0 += band {
+				y1 := y0 + band
+				if y1 > g.plasmaField.height {
+					y1 = g.plasmaField.height
+				}
+				wg.Add(1)
+				go func(y0, y1 int) {
+					defer wg.Done()
+					g.plasmaField.renderRows(y0, y1)
+				}(y0, y1)
+			}
+			wg.Wait()
+		}
+		g.plasmaField.patternReady = true
+	}
+
+	// Map the pattern through the (rotated) palette
+	offset := int(g.plasmaField.cycleOffset)
+	for i, idx := range g.plasmaField.indices {
+		c := g.plasmaField.lut[(int(idx)+offset)&255]
+		g.plasmaField.pixels[i*4] = c.R
+		g.plasmaField.pixels[i*4+1] = c.G
+		g.plasmaField.pixels[i*4+2] = c.B
+		g.plasmaField.pixels[i*4+3] = 255
+	}
+
+	// Upload the whole frame at once instead of one Set call per pixel. The pixels act
+	// as the back buffer: they are complete before the upload, so the buffer goes from
+	// one whole frame to the next and never shows a partly drawn one.
+	g.plasmaField.buffer.WritePixels(g.plasmaField.pixels)
+}
+
+// toggleCycling switches between the moving plasma and palette cycling on a frozen pattern
+func (p *PlasmaField) toggleCycling() {
+	p.cycling = !p.cycling
+	p.patternReady = false
+}
+
+// renderRows computes the plasma palette indices for rows y0 to y1 (exclusive).
+// Each pixel is independent, so bands can be rendered concurrently.
+func (p *PlasmaField) renderRows(y0, y1 int) {
+	for y := y0; y < y1; y++ {
+		py := float64(y) / p.detail
+		for x := 0; x < p.width; x++ {
+			px := float64(x) / p.detail
+
+			// Multiple sine waves for complex patterns
+			v1 := math.Sin(px*0.02 + p.time)
+			v2 := math.Sin(py*0.03 + p.time*1.5)
+			v3 := math.Sin(math.Sqrt(px*px+py*py)*0.01 + p.time*0.5)
+			v4 := math.Sin((px*0.01 + py*0.01) + p.time*2)
+
+			v := (v1 + v2 + v3 + v4) / 4
+
+			// Palette index for this value
+			idx := int((v + 1) * 127.5)
+			if idx > 255 {
+				idx = 255
+			}
+			p.indices[y*p.width+x] = uint8(idx)
+		}
+	}
+}
+
+// setPalette builds the 256-entry lookup table for the palette and uploads it for the shader
+func (p *PlasmaField) setPalette(palette PlasmaPalette) {
+	p.Palette = palette
+
+	for i := range p.lut {
+		t := float64(i) / 255
+		var r, g, b float64
+
+		switch palette {
+		case PaletteFire:
+			r, g, b = clamp01(t*3), clamp01(t*3-1), clamp01(t*3-2)
+		case PaletteIce:
+			r, g, b = clamp01(t*3-2), clamp01(t*3-1), clamp01(t*3)
+		case PaletteRainbow:
+			r, g, b = hueToRGB(t)
+		case PaletteGrayscale:
+			r, g, b = t, t, t
+		default:
+			// Three phase-shifted sines over the plasma value range
+			v := t*2 - 1
+			r = (math.Sin(v*math.Pi) + 1) * 127 / 255
+			g = (math.Sin(v*math.Pi+2*math.Pi/3) + 1) * 127 / 255
+			b = (math.Sin(v*math.Pi+4*math.Pi/3) + 1) * 127 / 255
+		}
+
+		p.lut[i] = color.RGBA{uint8(r * 255), uint8(g * 255), uint8(b * 255), 255}
+	}
+
+	pixels := make([]byte, 4*p.width*p.height)
+	for i, c := range p.lut {
+		pixels[i*4] = c.R
+		pixels[i*4+1] = c.G
+		pixels[i*4+2] = c.B
+		pixels[i*4+3] = c.A
+	}
+	p.paletteImg.WritePixels(pixels)
+}
+
+// hueToRGB converts a hue in [0, 1) to a fully saturated color with components in [0, 1]
+func hueToRGB(h float64) (r, g, b float64) {
+	h -= math.Floor(h)
+	r = math.Abs(h*6-3) - 1
+	g = 2 - math.Abs(h*6-2)
+	b = 2 - math.Abs(h*6-4)
+	return clamp01(r), clamp01(g), clamp01(b)
+}
+
+// clamp01 clamps v to the [0, 1] range
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
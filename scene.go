@@ -0,0 +1,254 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// Glitch transition parameters, at full intensity
+	glitchSlices    = 24   // Shifted horizontal slices
+	glitchMaxShift  = 80.0 // Largest slice offset in pixels
+	glitchSplit     = 12.0 // Red channel offset in pixels
+	glitchSplitOdds = 0.5  // Chance per frame of a channel split
+	glitchBlocks    = 12   // Blocks copied out of place
+)
+
+// Scene is one part of the demo timeline
+type Scene interface {
+	Enter()                    // Called when the scene becomes current
+	Update()                   // Advances the scene by one frame
+	Draw(screen *ebiten.Image) // Renders the scene
+	Done() bool                // Reports that the scene has finished before its duration
+}
+
+// TransitionMode selects how a scene replaces the previous one
+type TransitionMode int
+
+const (
+	TransitionCut       TransitionMode = iota // Switch immediately
+	TransitionFadeBlack                       // Fade the previous scene out to black, then the new one in
+	TransitionDissolve                        // Blend the previous scene directly into the new one
+	TransitionGlitch                          // Cut to the new scene torn by glitches that settle down
+)
+
+// transitionModes maps the configuration names of the transition modes
+var transitionModes = map[string]TransitionMode{
+	"cut":      TransitionCut,
+	"black":    TransitionFadeBlack,
+	"dissolve": TransitionDissolve,
+	"glitch":   TransitionGlitch,
+}
+
+// Transition describes the change into a scene
+type Transition struct {
+	Mode   TransitionMode
+	Frames int
+}
+
+// SequenceEntry schedules a scene in the timeline
+type SequenceEntry struct {
+	Scene      Scene
+	Duration   int        // Frames before moving on, 0 to run until the scene is done
+	Transition Transition // How this scene replaces the previous one
+}
+
+// Sequencer plays scenes one after the other. The last scene keeps running.
+type Sequencer struct {
+	entries []SequenceEntry
+	current int
+	frame   int // Frames spent in the current scene
+
+	// Transition in progress, with previous < 0 when there is none.
+	// The previous scene is frozen and only drawn while it fades out.
+	previous    int
+	transFrame  int
+	outCanvas   *ebiten.Image
+	inCanvas    *ebiten.Image
+	compositeOp *ebiten.DrawImageOptions
+	rng         *rand.Rand // Glitch slices and blocks
+}
+
+// NewSequencer creates a sequencer starting with the first entry, with rng driving the glitch transition
+func NewSequencer(rng *rand.Rand, entries ...SequenceEntry) *Sequencer {
+	s := &Sequencer{
+		entries:     entries,
+		previous:    -1,
+		outCanvas:   ebiten.NewImage(screenWidth, screenHeight),
+		inCanvas:    ebiten.NewImage(screenWidth, screenHeight),
+		compositeOp: &ebiten.DrawImageOptions{},
+		rng:         rng,
+	}
+	if len(entries) > 0 {
+		entries[0].Scene.Enter()
+	}
+	return s
+}
+
+// Restart goes back to the first scene
+func (s *Sequencer) Restart() {
+	s.current = 0
+	s.frame = 0
+	s.previous = -1
+	if len(s.entries) > 0 {
+		s.entries[0].Scene.Enter()
+	}
+}
+
+// Update moves to the next scene when the current one has ended, then updates it
+func (s *Sequencer) Update() {
+	if len(s.entries) == 0 {
+		return
+	}
+
+	entry := s.entries[s.current]
+	ended := entry.Scene.Done() || (entry.Duration > 0 && s.frame >= entry.Duration)
+	if ended && s.current < len(s.entries)-1 {
+		s.previous = s.current
+		s.current++
+		s.frame = 0
+		s.transFrame = 0
+		entry = s.entries[s.current]
+		entry.Scene.Enter()
+	}
+
+	// End the transition once its frames have elapsed
+	if s.previous >= 0 {
+		if entry.Transition.Mode == TransitionCut || s.transFrame >= entry.Transition.Frames {
+			s.previous = -1
+		}
+		s.transFrame++
+	}
+
+	entry.Scene.Update()
+	s.frame++
+}
+
+// Draw renders the current scene, blended with the previous one during a transition
+func (s *Sequencer) Draw(screen *ebiten.Image) {
+	if len(s.entries) == 0 {
+		return
+	}
+
+	current := s.entries[s.current]
+	if s.previous < 0 {
+		current.Scene.Draw(screen)
+		return
+	}
+
+	previous := s.entries[s.previous]
+	t := float32(s.transFrame) / float32(current.Transition.Frames)
+	screen.Fill(color.Black)
+
+	switch current.Transition.Mode {
+	case TransitionGlitch:
+		s.inCanvas.Clear()
+		current.Scene.Draw(s.inCanvas)
+		s.applyGlitch(s.inCanvas, float64(1-t))
+		s.compositeOp.ColorScale.Reset()
+		screen.DrawImage(s.inCanvas, s.compositeOp)
+	case TransitionFadeBlack:
+		// Previous scene fades out during the first half, the new one fades in during the second
+		if t < 0.5 {
+			s.composite(screen, previous.Scene, s.outCanvas, 1-2*t)
+		} else {
+			s.composite(screen, current.Scene, s.inCanvas, 2*t-1)
+		}
+	default:
+		// Drawing the new scene over the old one with alpha t gives old*(1-t) + new*t
+		s.composite(screen, previous.Scene, s.outCanvas, 1)
+		s.composite(screen, current.Scene, s.inCanvas, t)
+	}
+}
+
+// composite draws a scene into its canvas, then onto the screen with the given alpha
+func (s *Sequencer) composite(screen *ebiten.Image, scene Scene, canvas *ebiten.Image, alpha float32) {
+	canvas.Clear()
+	scene.Draw(canvas)
+
+	s.compositeOp.ColorScale.Reset()
+	s.compositeOp.ColorScale.ScaleAlpha(alpha)
+	screen.DrawImage(canvas, s.compositeOp)
+}
+
+// applyGlitch tears img with shifted slices, a red channel split and misplaced blocks,
+// more of each as intensity goes from 0 to 1. outCanvas holds the untouched copy.
+func (s *Sequencer) applyGlitch(img *ebiten.Image, intensity float64) {
+	if intensity <= 0 {
+		return
+	}
+
+	src := s.outCanvas
+	src.Clear()
+	s.compositeOp.GeoM.Reset()
+	s.compositeOp.ColorScale.Reset()
+	src.DrawImage(img, s.compositeOp)
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	op := s.compositeOp
+
+	// Shift horizontal slices sideways
+	for i := 0; i < int(intensity*glitchSlices); i++ {
+		y := s.rng.Intn(h)
+		sliceHeight := 4 + s.rng.Intn(36)
+		op.GeoM.Reset()
+		op.GeoM.Translate((s.rng.Float64()*2-1)*glitchMaxShift*intensity, float64(y))
+		img.DrawImage(src.SubImage(image.Rect(0, y, w, y+sliceHeight)).(*ebiten.Image), op)
+	}
+
+	// Now and then, add a shifted copy of the red channel
+	if s.rng.Float64() < glitchSplitOdds*intensity {
+		op.GeoM.Reset()
+		op.GeoM.Translate(glitchSplit*intensity, 0)
+		op.ColorScale.Scale(1, 0, 0, 1)
+		op.Blend = ebiten.BlendLighter
+		img.DrawImage(src, op)
+		op.ColorScale.Reset()
+		op.Blend = ebiten.BlendSourceOver
+	}
+
+	// Copy blocks to the wrong place
+	for i := 0; i < int(intensity*glitchBlocks); i++ {
+		size := 16 + s.rng.Intn(48)
+		sx, sy := s.rng.Intn(w), s.rng.Intn(h)
+		op.GeoM.Reset()
+		op.GeoM.Translate(float64(s.rng.Intn(w)), float64(s.rng.Intn(h)))
+		img.DrawImage(src.SubImage(image.Rect(sx, sy, sx+size, sy+size)).(*ebiten.Image), op)
+	}
+	op.GeoM.Reset()
+}
+
+// introScene scrolls the intro text through the CRT shader until it has gone by
+type introScene struct {
+	g *Game
+}
+
+func (s *introScene) Enter()                    {}
+func (s *introScene) Update()                   { s.g.animIntro() }
+func (s *introScene) Draw(screen *ebiten.Image) { s.g.drawIntro(screen) }
+func (s *introScene) Done() bool                { return s.g.introComplete }
+
+// demoScene runs the main demo and starts the music
+type demoScene struct {
+	g *Game
+}
+
+func (s *demoScene) Enter() {}
+
+func (s *demoScene) Update() {
+	g := s.g
+
+	// Start music when demo begins, or again after a pause unless only the music is paused
+	if g.audioPlayer != nil && !g.musicPaused && !g.audioPlayer.IsPlaying() {
+		g.audioPlayer.Play()
+	}
+
+	// Update main demo
+	g.updateMainDemo()
+}
+
+func (s *demoScene) Draw(screen *ebiten.Image) { s.g.drawDemo(screen) }
+func (s *demoScene) Done() bool                { return false }
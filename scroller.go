@@ -0,0 +1,798 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// Font parameters
+	fontHeight     = 36
+	fontWidth      = 48 // Average width for font characters
+	introFontScale = 2.0
+	demoFontScale  = 1.5 // Reduced for better readability
+
+	// Characters missing from the font are drawn with this glyph, a blank.
+	// font.png only has space, ! " ' ( ) + , - . 0-9 : ; < = > ? and A-Z;
+	// its cells for # $ % & * / @ _ and the other symbols are empty.
+	fallbackGlyph = ' '
+
+	// Advance at scale 1 of missing characters when the font lacks even the fallback glyph
+	missingGlyphWidth = 32
+
+	// Blank run around the scroll texts, so they enter and leave on an empty line
+	scrollPadding = "     "
+
+	// Hue difference between neighbouring scroller characters in rainbow mode
+	rainbowCharSpread = 0.04
+
+	// Typewriter mode parameters
+	typewriterMargin     = 16 // Horizontal space kept free on each side of a line
+	typewriterFadeFrames = 8  // Frames for a revealed character to fade in
+	typewriterHoldFrames = 90 // Frames a completed line stays before the next one
+
+	// Bounce mode parameters
+	bounceAmplitude = 40   // Vertical travel of each character in pixels
+	bounceSpeed     = 4    // Phase advance in radians per second of demo time
+	bounceCharPhase = 0.35 // Phase difference between neighbouring characters
+	bouncePulse     = 0.25 // Scale change of the pulsing characters
+
+	// Chromatic fringe of the wave scroller: pixels of red and blue shift per pixel
+	// of wave offset, and the widest shift whatever the wave
+	scrollFringeScale = 0.08
+	scrollFringeMax   = 6.0
+
+	// Gap in pixels between the wave scroller and its floor reflection
+	reflectionGap = 2
+
+	// Credits line parameters
+	creditsFontScale = 0.5
+	creditsSpeed     = 1.0
+)
+
+// accentFolding maps accented capitals and typographic punctuation, which the font lacks,
+// to the characters it has
+var accentFolding = map[rune]string{
+	'À': "A", 'Á': "A", 'Â': "A", 'Ã': "A", 'Ä': "A", 'Å': "A",
+	'Ç': "C",
+	'É': "E", 'È': "E", 'Ê': "E", 'Ë': "E",
+	'Ì': "I", 'Í': "I", 'Î': "I", 'Ï': "I",
+	'Ñ': "N",
+	'Ò': "O", 'Ó': "O", 'Ô': "O", 'Õ': "O", 'Ö': "O", 'Ø': "O",
+	'Ù': "U", 'Ú': "U", 'Û': "U", 'Ü': "U",
+	'Ý': "Y", 'Ÿ': "Y",
+	'Œ': "OE", 'Æ': "AE", 'ß': "SS",
+
+	// Typographic punctuation, common in French text
+	'’': "'", '‘': "'", '‚': "'",
+	'“': "\"", '”': "\"", '„': "\"", '«': "\"", '»': "\"", '‹': "'", '›': "'",
+	'–': "-", '—': "-", '‐': "-",
+	'…':      "...",
+	'\u00a0': " ", '\u202f': " ", // No-break spaces, as before French ! ? : ;
+}
+
+// fontText uppercases s and replaces accented letters and typographic punctuation with
+// the characters of the bitmap font. Every text drawn with the font goes through it.
+func fontText(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(s) {
+		if folded, ok := accentFolding[r]; ok {
+			b.WriteString(folded)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Letter represents a character in the bitmap font
+type Letter struct {
+	x, y  int
+	width int
+}
+
+// fontLayout places each glyph in font.png, fontHeight pixels tall
+var fontLayout = []struct {
+	char  rune
+	x, y  int
+	width int
+}{
+	{' ', 0, 0, 32},
+	{'!', 48, 0, 16},
+	{'"', 96, 0, 32},
+	{'\'', 336, 0, 16},
+	{'(', 384, 0, 32},
+	{')', 432, 0, 32},
+	{'+', 48, 36, 48},
+	{',', 96, 36, 16},
+	{'-', 144, 36, 32},
+	{'.', 192, 36, 16},
+	{'0', 288, 36, 48},
+	{'1', 336, 36, 48},
+	{'2', 384, 36, 48},
+	{'3', 432, 36, 48},
+	{'4', 0, 72, 48},
+	{'5', 48, 72, 48},
+	{'6', 96, 72, 48},
+	{'7', 144, 72, 48},
+	{'8', 192, 72, 48},
+	{'9', 240, 72, 48},
+	{':', 288, 72, 16},
+	{';', 336, 72, 16},
+	{'<', 384, 72, 32},
+	{'=', 432, 72, 32},
+	{'>', 0, 108, 32},
+	{'?', 48, 108, 48},
+	{'A', 144, 108, 48},
+	{'B', 192, 108, 48},
+	{'C', 240, 108, 48},
+	{'D', 288, 108, 48},
+	{'E', 336, 108, 48},
+	{'F', 384, 108, 48},
+	{'G', 432, 108, 48},
+	{'H', 0, 144, 48},
+	{'I', 48, 144, 16},
+	{'J', 96, 144, 48},
+	{'K', 144, 144, 48},
+	{'L', 192, 144, 48},
+	{'M', 240, 144, 48},
+	{'N', 288, 144, 48},
+	{'O', 336, 144, 48},
+	{'P', 384, 144, 48},
+	{'Q', 432, 144, 48},
+	{'R', 0, 180, 48},
+	{'S', 48, 180, 48},
+	{'T', 96, 180, 48},
+	{'U', 144, 180, 48},
+	{'V', 192, 180, 48},
+	{'W', 240, 180, 48},
+	{'X', 288, 180, 48},
+	{'Y', 336, 180, 48},
+	{'Z', 384, 180, 48},
+	{'#', 432, 180, 48}, // Special character for logo
+}
+
+// fallbackGlyphs are 5x7 bitmaps, one row per byte with the leftmost pixel in bit 4, drawn at the
+// fontLayout positions when font.png can't be loaded. The space is left out since it stays blank.
+var fallbackGlyphs = map[rune][7]uint8{
+	'!':  {0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00000, 0b00100},
+	'"':  {0b01010, 0b01010, 0b01010, 0b00000, 0b00000, 0b00000, 0b00000},
+	'\'': {0b00100, 0b00100, 0b01000, 0b00000, 0b00000, 0b00000, 0b00000},
+	'(':  {0b00010, 0b00100, 0b01000, 0b01000, 0b01000, 0b00100, 0b00010},
+	')':  {0b01000, 0b00100, 0b00010, 0b00010, 0b00010, 0b00100, 0b01000},
+	'+':  {0b00000, 0b00100, 0b00100, 0b11111, 0b00100, 0b00100, 0b00000},
+	',':  {0b00000, 0b00000, 0b00000, 0b00000, 0b01100, 0b00100, 0b01000},
+	'-':  {0b00000, 0b00000, 0b00000, 0b11111, 0b00000, 0b00000, 0b00000},
+	'.':  {0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b01100, 0b01100},
+	'0':  {0b01110, 0b10001, 0b10011, 0b10101, 0b11001, 0b10001, 0b01110},
+	'1':  {0b00100, 0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'2':  {0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b01000, 0b11111},
+	'3':  {0b11111, 0b00010, 0b00100, 0b00010, 0b00001, 0b10001, 0b01110},
+	'4':  {0b00010, 0b00110, 0b01010, 0b10010, 0b11111, 0b00010, 0b00010},
+	'5':  {0b11111, 0b10000, 0b11110, 0b00001, 0b00001, 0b10001, 0b01110},
+	'6':  {0b00110, 0b01000, 0b10000, 0b11110, 0b10001, 0b10001, 0b01110},
+	'7':  {0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b01000, 0b01000},
+	'8':  {0b01110, 0b10001, 0b10001, 0b01110, 0b10001, 0b10001, 0b01110},
+	'9':  {0b01110, 0b10001, 0b10001, 0b01111, 0b00001, 0b00010, 0b01100},
+	':':  {0b00000, 0b01100, 0b01100, 0b00000, 0b01100, 0b01100, 0b00000},
+	';':  {0b00000, 0b01100, 0b01100, 0b00000, 0b01100, 0b00100, 0b01000},
+	'<':  {0b00010, 0b00100, 0b01000, 0b10000, 0b01000, 0b00100, 0b00010},
+	'=':  {0b00000, 0b00000, 0b11111, 0b00000, 0b11111, 0b00000, 0b00000},
+	'>':  {0b01000, 0b00100, 0b00010, 0b00001, 0b00010, 0b00100, 0b01000},
+	'?':  {0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b00000, 0b00100},
+	'A':  {0b01110, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001, 0b10001},
+	'B':  {0b11110, 0b10001, 0b10001, 0b11110, 0b10001, 0b10001, 0b11110},
+	'C':  {0b01110, 0b10001, 0b10000, 0b10000, 0b10000, 0b10001, 0b01110},
+	'D':  {0b11100, 0b10010, 0b10001, 0b10001, 0b10001, 0b10010, 0b11100},
+	'E':  {0b11111, 0b10000, 0b10000, 0b11110, 0b10000, 0b10000, 0b11111},
+	'F':  {0b11111, 0b10000, 0b10000, 0b11110, 0b10000, 0b10000, 0b10000},
+	'G':  {0b01110, 0b10001, 0b10000, 0b10111, 0b10001, 0b10001, 0b01111},
+	'H':  {0b10001, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001, 0b10001},
+	'I':  {0b01110, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'J':  {0b00111, 0b00010, 0b00010, 0b00010, 0b00010, 0b10010, 0b01100},
+	'K':  {0b10001, 0b10010, 0b10100, 0b11000, 0b10100, 0b10010, 0b10001},
+	'L':  {0b10000, 0b10000, 0b10000, 0b10000, 0b10000, 0b10000, 0b11111},
+	'M':  {0b10001, 0b11011, 0b10101, 0b10101, 0b10001, 0b10001, 0b10001},
+	'N':  {0b10001, 0b10001, 0b11001, 0b10101, 0b10011, 0b10001, 0b10001},
+	'O':  {0b01110, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01110},
+	'P':  {0b11110, 0b10001, 0b10001, 0b11110, 0b10000, 0b10000, 0b10000},
+	'Q':  {0b01110, 0b10001, 0b10001, 0b10001, 0b10101, 0b10010, 0b01101},
+	'R':  {0b11110, 0b10001, 0b10001, 0b11110, 0b10100, 0b10010, 0b10001},
+	'S':  {0b01111, 0b10000, 0b10000, 0b01110, 0b00001, 0b00001, 0b11110},
+	'T':  {0b11111, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100},
+	'U':  {0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01110},
+	'V':  {0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01010, 0b00100},
+	'W':  {0b10001, 0b10001, 0b10001, 0b10101, 0b10101, 0b10101, 0b01010},
+	'X':  {0b10001, 0b10001, 0b01010, 0b00100, 0b01010, 0b10001, 0b10001},
+	'Y':  {0b10001, 0b10001, 0b10001, 0b01010, 0b00100, 0b00100, 0b00100},
+	'Z':  {0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b10000, 0b11111},
+	'#':  {0b01010, 0b01010, 0b11111, 0b01010, 0b11111, 0b01010, 0b01010},
+}
+
+// ScrollMode selects how the main demo text is animated
+type ScrollMode int
+
+const (
+	ScrollModeWave       ScrollMode = iota // Scroller with a horizontal wave, TCB-Replicants style
+	ScrollModeBounce                       // Sine scroller, each character bobbing up and down
+	ScrollModeTypewriter                   // Lines typed in place one character at a time
+	scrollModeCount
+)
+
+// scrollModes maps the configuration names of the scroll modes
+var scrollModes = map[string]ScrollMode{
+	"wave":       ScrollModeWave,
+	"bounce":     ScrollModeBounce,
+	"typewriter": ScrollModeTypewriter,
+}
+
+// WaveTerm is one sine component of a scroll wave segment
+type WaveTerm struct {
+	Amplitude float64 `json:"amplitude"` // Offset in pixels
+	FreqDeg   float64 `json:"freq_deg"`  // Phase step per line in degrees
+	PhaseDeg  float64 `json:"phase_deg"` // Starting phase in degrees, 90 for a cosine
+}
+
+// WaveSegment is a run of scroll wave lines, each the sum of the terms
+type WaveSegment struct {
+	Count int        `json:"count"`
+	Terms []WaveTerm `json:"terms"`
+}
+
+// waveLength returns the number of lines the segments generate
+func waveLength(segments []WaveSegment) int {
+	n := 0
+	for _, s := range segments {
+		if s.Count > 0 {
+			n += s.Count
+		}
+	}
+	return n
+}
+
+// buildScrollWave generates the horizontal offsets of the scroll wave, segment after segment
+func buildScrollWave(segments []WaveSegment) []float64 {
+	wave := make([]float64, 0, waveLength(segments))
+	for _, s := range segments {
+		for i := 0; i < s.Count; i++ {
+			x := 0.0
+			for _, t := range s.Terms {
+				x += t.Amplitude * math.Sin((float64(i)*t.FreqDeg+t.PhaseDeg)/180.0*math.Pi)
+			}
+			wave = append(wave, x)
+		}
+	}
+	return wave
+}
+
+// ScrollChar represents a character in the scrolling text
+type ScrollChar struct {
+	char  rune
+	x     float64
+	baseY float64
+	waveY float64
+	scale float64
+	alpha float64
+}
+
+// initFontData initializes the bitmap font character data
+func (g *Game) initFontData() {
+	for _, d := range fontLayout {
+		g.letterData[d.char] = &Letter{
+			x:     d.x,
+			y:     d.y,
+			width: d.width,
+		}
+	}
+}
+
+// validateFontData removes the glyphs lying outside the font image, so a font.png with
+// another layout draws them as blanks instead of reading out of bounds
+func (g *Game) validateFontData() {
+	bounds := g.fontImg.Bounds()
+	for char, letter := range g.letterData {
+		rect := image.Rect(letter.x, letter.y, letter.x+letter.width, letter.y+fontHeight)
+		if !rect.In(bounds) {
+			log.Printf("Glyph %q at %v is outside the %dx%d font image, skipping it", char, rect, bounds.Dx(), bounds.Dy())
+			delete(g.letterData, char)
+		}
+	}
+}
+
+// initScrollWave builds the scroll wave from the configured segments
+func (g *Game) initScrollWave() {
+	g.scrollWave = buildScrollWave(g.cfg.ScrollWave)
+}
+
+// newFallbackFont draws the fallbackGlyphs into a font image of the given size, each glyph
+// stretched to fill its fontLayout cell so the font is laid out like font.png
+func newFallbackFont(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	pixelHeight := fontHeight / 7
+	for _, d := range fontLayout {
+		rows, ok := fallbackGlyphs[d.char]
+		if !ok {
+			continue
+		}
+
+		pixelWidth := max(1, (d.width-4)/5)
+		left := d.x + (d.width-5*pixelWidth)/2
+		top := d.y + (fontHeight-7*pixelHeight)/2
+		for row, bits := range rows {
+			for col := 0; col < 5; col++ {
+				if bits&(0x10>>col) == 0 {
+					continue
+				}
+				x := left + col*pixelWidth
+				y := top + row*pixelHeight
+				draw.Draw(img, image.Rect(x, y, x+pixelWidth, y+pixelHeight), image.White, image.Point{}, draw.Src)
+			}
+		}
+	}
+	return img
+}
+
+// initCredits builds the music credits line from the YM metadata
+func (g *Game) initCredits() {
+	info := YMInfo{Name: "UNKNOWN", Author: "UNKNOWN"}
+	if g.music != nil {
+		info = g.music.Info()
+	}
+
+	seconds := info.DurationMs / 1000
+	credits := fmt.Sprintf("MUSIC: %s BY %s (%d:%02d)", info.Name, info.Author, seconds/60, seconds%60)
+	g.creditsRunes = []rune(fontText(credits))
+
+	g.creditsWidth = g.MeasureText(g.creditsRunes, creditsFontScale)
+	g.creditsX = screenWidth
+}
+
+// updateCredits scrolls the music credits line
+func (g *Game) updateCredits() {
+	g.creditsX -= creditsSpeed
+	if g.creditsX < -g.creditsWidth {
+		g.creditsX = screenWidth
+	}
+}
+
+// drawCredits draws the music credits line at the bottom of the screen
+func (g *Game) drawCredits(screen *ebiten.Image) {
+	y := float64(screenHeight) - fontHeight*creditsFontScale - 8
+	xPos := g.creditsX
+
+	for _, char := range g.creditsRunes {
+		letter := g.glyph(char)
+		if letter != nil && xPos > -float64(letter.width)*creditsFontScale && xPos < screenWidth {
+			srcRect := image.Rect(letter.x, letter.y, letter.x+letter.width, letter.y+fontHeight)
+			g.drawOp.GeoM.Reset()
+			g.drawOp.ColorScale.Reset()
+			g.drawOp.GeoM.Scale(creditsFontScale, creditsFontScale)
+			g.drawOp.GeoM.Translate(xPos, y)
+			screen.DrawImage(g.fontImg.SubImage(srcRect).(*ebiten.Image), g.drawOp)
+		}
+		xPos += g.glyphAdvance(char, creditsFontScale)
+	}
+}
+
+// drawScrollText draws the scrolling text TCB-Replicants style
+func (g *Game) drawScrollText() {
+	// Initialize wave if empty
+	if len(g.scrollWave) == 0 {
+		g.initScrollWave()
+	}
+
+	// Clear scroll canvas
+	g.scrollCanvas.Clear()
+
+	switch g.scrollMode {
+	case ScrollModeTypewriter:
+		g.drawTypewriter()
+		return
+	case ScrollModeBounce:
+		g.drawBounce()
+		return
+	}
+
+	// IMPORTANT: Draw text starting from canvas edge, not screen edge
+	// The canvas is wider than the screen to allow for wave distortion
+	startX := g.scrollStartX(g.scrollCanvas)
+	for i, ch := range g.scrollChars {
+		// Draw character if potentially visible
+		xPos := startX + ch.x
+		if xPos > -200 && xPos < float64(g.scrollCanvas.Bounds().Dx())+200 {
+			g.drawScrollGlyph(g.scrollCanvas, ch, i, xPos, 0)
+		}
+	}
+	g.applyScrollGradient(g.scrollCanvas)
+
+	// Apply horizontal wave distortion line by line
+	baseY := float64(g.stCanvas.Bounds().Dy()) - 100
+	scrollHeight := int(fontHeight * demoFontScale)
+
+	// Draw each line with horizontal offset
+	waveIndex := int(g.scrollOffset)
+
+	// The key is to draw from the scroll canvas to the screen canvas
+	// taking into account that the text position in scrollCanvas is different
+	for y := 0; y < scrollHeight/2; y++ {
+		// Get wave offset for this line
+		idx := (waveIndex + y) % len(g.scrollWave)
+		offsetX := g.scrollWave[idx]
+
+		// Calculate source position - this is the key fix
+		// We need to sample from the right part of the scroll canvas
+		srcX := int(offsetX) + 64 + (g.scrollCanvas.Bounds().Dx()-g.stCanvas.Bounds().Dx())/2
+
+		// Source rectangle from scroll canvas
+		srcRect := image.Rect(srcX, y*2, srcX+g.stCanvas.Bounds().Dx(), (y+1)*2)
+
+		// Ensure we stay within bounds
+		if srcRect.Min.X < 0 {
+			srcRect.Min.X = 0
+		}
+		if srcRect.Max.X > g.scrollCanvas.Bounds().Dx() {
+			srcRect.Max.X = g.scrollCanvas.Bounds().Dx()
+		}
+
+		if srcRect.Min.X < srcRect.Max.X && srcRect.Dx() > 0 {
+			line := g.scrollCanvas.SubImage(srcRect).(*ebiten.Image)
+			op := g.drawOp
+
+			// Red and blue copies shifted apart under the line, so they show as fringes
+			// on its edges. The shift follows the offset, flipping sides with the wave.
+			if g.scrollFringe {
+				fringe := math.Max(-scrollFringeMax, math.Min(scrollFringeMax, offsetX*scrollFringeScale))
+				if math.Abs(fringe) >= 0.5 {
+					op.GeoM.Reset()
+					op.ColorScale.Reset()
+					op.ColorScale.Scale(1, 0, 0, 1)
+					op.GeoM.Translate(fringe, baseY+float64(y*2))
+					g.stCanvas.DrawImage(line, op)
+
+					op.GeoM.Reset()
+					op.ColorScale.Reset()
+					op.ColorScale.Scale(0, 0, 1, 1)
+					op.GeoM.Translate(-fringe, baseY+float64(y*2))
+					g.stCanvas.DrawImage(line, op)
+				}
+			}
+
+			op.GeoM.Reset()
+			op.ColorScale.Reset()
+			op.GeoM.Translate(0, baseY+float64(y*2))
+
+			g.stCanvas.DrawImage(line, op)
+			g.drawScrollReflection(line, baseY+float64(y*2), baseY+float64(scrollHeight))
+		}
+	}
+}
+
+// drawScrollReflection mirrors a wave scroller line drawn at top below the band's bottom
+// edge, fading it out with the distance from the edge.
+// The reflection stays in the space left under the band, above the bottom of stCanvas,
+// so the logo spiral drawn afterwards still covers it.
+func (g *Game) drawScrollReflection(line *ebiten.Image, top, bottom float64) {
+	height := math.Min(float64(g.cfg.ReflectionHeight), float64(g.stCanvas.Bounds().Dy())-bottom-reflectionGap)
+	if g.cfg.ReflectionOpacity <= 0 || height <= 0 {
+		return
+	}
+
+	// Distance from the bottom of the line to the bottom of the band
+	distance := bottom - top - float64(line.Bounds().Dy())
+	if distance >= height {
+		return
+	}
+
+	op := g.drawOp
+	op.GeoM.Reset()
+	op.ColorScale.Reset()
+	op.GeoM.Scale(1, -1)
+	op.GeoM.Translate(0, bottom+reflectionGap+distance+float64(line.Bounds().Dy()))
+	op.ColorScale.ScaleAlpha(float32(g.cfg.ReflectionOpacity * (1 - distance/height)))
+	g.stCanvas.DrawImage(line, op)
+}
+
+// drawBounce draws the sine scroller, the characters following their waveY offsets
+func (g *Game) drawBounce() {
+	g.bounceCanvas.Clear()
+
+	startX := g.scrollStartX(g.bounceCanvas)
+	for i, ch := range g.scrollChars {
+		xPos := startX + ch.x
+		if xPos > -200 && xPos < float64(g.bounceCanvas.Bounds().Dx())+200 {
+			g.drawScrollGlyph(g.bounceCanvas, ch, i, xPos, bounceAmplitude+ch.waveY)
+		}
+	}
+	g.applyScrollGradient(g.bounceCanvas)
+
+	// Centered on the band of the wave scroller
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(0, float64(g.stCanvas.Bounds().Dy())-100-bounceAmplitude)
+	g.stCanvas.DrawImage(g.bounceCanvas, op)
+}
+
+// scrollStartX returns where the scroll text starts on a canvas. scrollX counts up
+// from 0 to scrollWidth in both directions: right to left enters at the right edge,
+// left to right starts where right to left wraps and ends off the right edge.
+func (g *Game) scrollStartX(canvas *ebiten.Image) float64 {
+	if g.scrollReverse {
+		return float64(canvas.Bounds().Dx()) - g.scrollWidth + g.scrollX
+	}
+	return float64(canvas.Bounds().Dx()) - g.scrollX
+}
+
+// SetScrollText replaces the main demo text and starts it over. The text is measured
+// and laid out here, the only place it changes, instead of on every frame.
+func (g *Game) SetScrollText(text string) {
+	g.cfg.ScrollText = text
+	g.scrollText = scrollPadding + scrollPadding + text + scrollPadding + scrollPadding + scrollPadding + scrollPadding
+	g.scrollTextRunes = []rune(fontText(g.scrollText))
+
+	g.scrollWidth = g.MeasureText(g.scrollTextRunes, demoFontScale)
+	g.scrollChars = g.layoutScrollChars(g.scrollTextRunes)
+	g.typewriterLines = g.layoutTypewriter(text)
+
+	g.scrollX = 0
+	g.resetTypewriter()
+}
+
+// layoutScrollChars places each character of the scroll text at its advance from the start
+func (g *Game) layoutScrollChars(runes []rune) []ScrollChar {
+	chars := make([]ScrollChar, len(runes))
+	x := 0.0
+	for i, char := range runes {
+		chars[i] = ScrollChar{char: char, x: x, scale: 1, alpha: 1}
+		x += g.glyphAdvance(char, demoFontScale)
+	}
+	return chars
+}
+
+// resetScrollChars puts the scroller characters back on the baseline at their normal size
+func (g *Game) resetScrollChars() {
+	for i := range g.scrollChars {
+		g.scrollChars[i].waveY = 0
+		g.scrollChars[i].scale = 1
+	}
+}
+
+// updateBounce moves each scroller character along its own phase of a sine wave,
+// optionally pulsing its size
+func (g *Game) updateBounce() {
+	for i := range g.scrollChars {
+		phase := g.demoTime*bounceSpeed + float64(i)*bounceCharPhase
+		g.scrollChars[i].waveY = math.Sin(phase) * bounceAmplitude
+		if g.cfg.ScrollPulse {
+			g.scrollChars[i].scale = 1 + bouncePulse*math.Sin(2*phase)
+		}
+	}
+}
+
+// drawScrollGlyph draws one character at x, y on dst. The character is scaled around
+// its center, faded by its alpha, and the index selects its rainbow hue.
+func (g *Game) drawScrollGlyph(dst *ebiten.Image, ch ScrollChar, index int, x, y float64) {
+	letter := g.glyph(ch.char)
+	if letter == nil {
+		return
+	}
+
+	srcRect := image.Rect(letter.x, letter.y, letter.x+letter.width, letter.y+fontHeight)
+	op := g.drawOp
+	op.GeoM.Reset()
+	op.ColorScale.Reset()
+	op.GeoM.Scale(demoFontScale, demoFontScale)
+	if ch.scale != 1 {
+		cx := float64(letter.width) * demoFontScale / 2
+		cy := fontHeight * demoFontScale / 2
+		op.GeoM.Translate(-cx, -cy)
+		op.GeoM.Scale(ch.scale, ch.scale)
+		op.GeoM.Translate(cx, cy)
+	}
+	op.GeoM.Translate(x, y)
+
+	// Rainbow tint cycling along the text and over time
+	if g.scrollRainbow {
+		r, gr, b := hueToRGB(float64(index)*rainbowCharSpread + g.demoTime*g.cfg.RainbowSpeed)
+		op.ColorScale.Scale(float32(r), float32(gr), float32(b), 1)
+	}
+	op.ColorScale.ScaleAlpha(float32(ch.alpha))
+
+	dst.DrawImage(g.fontImg.SubImage(srcRect).(*ebiten.Image), op)
+}
+
+// applyScrollGradient multiplies the vertical gradient, stretched to the canvas height,
+// into the glyph colors. The canvas rows map to fixed screen rows, so the gradient
+// doesn't move with the text.
+func (g *Game) applyScrollGradient(canvas *ebiten.Image) {
+	if !g.cfg.ScrollGradient {
+		return
+	}
+
+	op := &ebiten.DrawImageOptions{Blend: multiplyBlend}
+	op.GeoM.Scale(float64(canvas.Bounds().Dx()), float64(canvas.Bounds().Dy())/float64(g.scrollGradient.Bounds().Dy()))
+	canvas.DrawImage(g.scrollGradient, op)
+}
+
+// layoutTypewriter wraps text into centered lines that fit the canvas, with every
+// character at its final position
+func (g *Game) layoutTypewriter(text string) [][]ScrollChar {
+	maxWidth := float64(stCanvasWidth - 2*typewriterMargin)
+
+	var lineTexts []string
+	line := ""
+	for _, word := range strings.Fields(fontText(text)) {
+		next := word
+		if line != "" {
+			next = line + " " + word
+		}
+		// A word wider than a whole line gets a line of its own
+		if line != "" && g.MeasureText([]rune(next), demoFontScale) > maxWidth {
+			lineTexts = append(lineTexts, line)
+			next = word
+		}
+		line = next
+	}
+	if line != "" {
+		lineTexts = append(lineTexts, line)
+	}
+
+	lines := make([][]ScrollChar, len(lineTexts))
+	for i, lineText := range lineTexts {
+		runes := []rune(lineText)
+		x := (stCanvasWidth - g.MeasureText(runes, demoFontScale)) / 2
+		chars := make([]ScrollChar, len(runes))
+		for j, char := range runes {
+			chars[j] = ScrollChar{char: char, x: x, scale: 1}
+			x += g.glyphAdvance(char, demoFontScale)
+		}
+		lines[i] = chars
+	}
+	return lines
+}
+
+// resetTypewriter starts the typewriter over from its first line
+func (g *Game) resetTypewriter() {
+	g.typewriterLine = 0
+	g.typewriterShown = 0
+	g.typewriterHold = 0
+	for _, line := range g.typewriterLines {
+		for i := range line {
+			line[i].alpha = 0
+		}
+	}
+}
+
+// updateTypewriter reveals the current line, holds it once complete, then moves on
+func (g *Game) updateTypewriter() {
+	if len(g.typewriterLines) == 0 {
+		return
+	}
+
+	line := g.typewriterLines[g.typewriterLine]
+	if g.typewriterShown < float64(len(line)) {
+		g.typewriterShown += g.cfg.TypewriterSpeed * animationStep
+	} else {
+		g.typewriterHold++
+		if g.typewriterHold >= typewriterHoldFrames {
+			for i := range line {
+				line[i].alpha = 0
+			}
+			g.typewriterLine = (g.typewriterLine + 1) % len(g.typewriterLines)
+			g.typewriterShown = 0
+			g.typewriterHold = 0
+			return
+		}
+	}
+
+	// Revealed characters fade in
+	for i := range line {
+		if float64(i) < g.typewriterShown {
+			line[i].alpha = math.Min(1, line[i].alpha+1.0/typewriterFadeFrames)
+		}
+	}
+}
+
+// drawTypewriter draws the current typewriter line in place of the scroller
+func (g *Game) drawTypewriter() {
+	if len(g.typewriterLines) == 0 {
+		return
+	}
+
+	for i, ch := range g.typewriterLines[g.typewriterLine] {
+		if ch.alpha > 0 {
+			g.drawScrollGlyph(g.scrollCanvas, ch, i, ch.x, ch.baseY)
+		}
+	}
+	g.applyScrollGradient(g.scrollCanvas)
+
+	// Same band as the wave scroller, without the distortion
+	srcRect := image.Rect(0, 0, g.stCanvas.Bounds().Dx(), g.scrollCanvas.Bounds().Dy())
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(0, float64(g.stCanvas.Bounds().Dy())-100)
+	g.stCanvas.DrawImage(g.scrollCanvas.SubImage(srcRect).(*ebiten.Image), op)
+}
+
+// multiplyBlend multiplies the destination color by the source color, keeping the destination alpha
+var multiplyBlend = ebiten.Blend{
+	BlendFactorSourceRGB:        ebiten.BlendFactorDestinationColor,
+	BlendFactorSourceAlpha:      ebiten.BlendFactorZero,
+	BlendFactorDestinationRGB:   ebiten.BlendFactorZero,
+	BlendFactorDestinationAlpha: ebiten.BlendFactorOne,
+	BlendOperationRGB:           ebiten.BlendOperationAdd,
+	BlendOperationAlpha:         ebiten.BlendOperationAdd,
+}
+
+// newGradientImage returns a 1×height image fading from the top color to the bottom color
+func newGradientImage(top, bottom [3]uint8, height int) *ebiten.Image {
+	pixels := make([]byte, 4*height)
+	for y := 0; y < height; y++ {
+		t := 0.0
+		if height > 1 {
+			t = float64(y) / float64(height-1)
+		}
+		for c := 0; c < 3; c++ {
+			pixels[4*y+c] = uint8(math.Round(lerpFloat(float64(top[c]), float64(bottom[c]), t)))
+		}
+		pixels[4*y+3] = 255
+	}
+
+	img := ebiten.NewImage(1, height)
+	img.WritePixels(pixels)
+	return img
+}
+
+// MeasureText returns the total advance width of the text drawn with the bitmap font at the given scale
+func (g *Game) MeasureText(runes []rune, scale float64) float64 {
+	width := 0.0
+	for _, char := range runes {
+		width += g.glyphAdvance(char, scale)
+	}
+	return width
+}
+
+// glyph returns the font glyph drawn for char. A character missing from the font is looked
+// up again as converted by fontText, then falls back to fallbackGlyph. It returns nil only
+// when the font lacks the fallback glyph too.
+func (g *Game) glyph(char rune) *Letter {
+	if letter, ok := g.letterData[char]; ok {
+		return letter
+	}
+	if converted := []rune(fontText(string(char))); len(converted) == 1 {
+		if letter, ok := g.letterData[converted[0]]; ok {
+			return letter
+		}
+	}
+	return g.letterData[fallbackGlyph]
+}
+
+// glyphAdvance returns the horizontal advance of one character at the given scale
+func (g *Game) glyphAdvance(char rune, scale float64) float64 {
+	if letter := g.glyph(char); letter != nil {
+		return float64(letter.width) * scale
+	}
+	return missingGlyphWidth * scale
+}
+
+// drawText draws a line of text with the bitmap font at the given position and scale
+func (g *Game) drawText(dst *ebiten.Image, text string, x, y, scale float64) {
+	for _, char := range text {
+		letter := g.glyph(char)
+		if letter == nil {
+			x += missingGlyphWidth * scale
+			continue
+		}
+
+		srcRect := image.Rect(letter.x, letter.y, letter.x+letter.width, letter.y+fontHeight)
+		g.drawOp.GeoM.Reset()
+		g.drawOp.ColorScale.Reset()
+		g.drawOp.GeoM.Scale(scale, scale)
+		g.drawOp.GeoM.Translate(x, y)
+		dst.DrawImage(g.fontImg.SubImage(srcRect).(*ebiten.Image), g.drawOp)
+		x += float64(letter.width) * scale
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestFontText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Salut à tous", "SALUT A TOUS"},
+		{"L’équipe d’été", "L'EQUIPE D'ETE"},
+		{"« Ça va ? »", "\" CA VA ? \""},
+		{"Cœur – fin…", "COEUR - FIN..."},
+		{"Vite\u202f!", "VITE !"},
+		{"16h00", "16H00"},
+	}
+	for _, tt := range tests {
+		if got := fontText(tt.in); got != tt.want {
+			t.Errorf("fontText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestGlyphFallback(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.NoAudio = true
+	cfg.Seed = 1
+	g := NewGame(cfg)
+	defer g.Cleanup()
+
+	if got, want := g.glyph('é'), g.letterData['E']; got != want {
+		t.Errorf("glyph('é') = %v, want the E glyph %v", got, want)
+	}
+	if got, want := g.glyph('’'), g.letterData['\'']; got != want {
+		t.Errorf("glyph('’') = %v, want the apostrophe glyph %v", got, want)
+	}
+	if got, want := g.glyph('/'), g.letterData[fallbackGlyph]; got != want || got == nil {
+		t.Errorf("glyph('/') = %v, want the fallback glyph %v", got, want)
+	}
+}
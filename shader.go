@@ -0,0 +1,334 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"log"
+	"math"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// Bloom parameters
+	defaultBloomThreshold = 0.7 // Luminance above which pixels glow
+	defaultBloomIntensity = 0.8 // Strength of the added glow
+
+	// Frames between modification checks of an external CRT shader
+	shaderPollInterval = 30
+
+	// Width in pixels of one red, green and blue triad of the phosphor mask
+	phosphorTriad = 3
+)
+
+// CRTParams holds the tunable CRT shader settings
+type CRTParams struct {
+	Curvature        float64 // Barrel distortion strength
+	ScanlineStrength float64 // Darkening of the scanlines
+	Aberration       float64 // Red/blue channel shift in texture coordinates
+	Flicker          float64 // Brightness flicker amount
+}
+
+// defaultCRTParams matches the original fixed CRT look
+var defaultCRTParams = CRTParams{
+	Curvature:        0.25,
+	ScanlineStrength: 0.04,
+	Aberration:       0.003,
+	Flicker:          0.05,
+}
+
+// crtParamSettings describes each CRT parameter for keyboard adjustment, in CRTParams field order
+var crtParamSettings = []struct {
+	name      string
+	step, max float64
+}{
+	{"curvature", 0.05, 1.0},
+	{"scanline strength", 0.01, 0.5},
+	{"aberration", 0.001, 0.02},
+	{"flicker", 0.01, 0.3},
+}
+
+// field returns a pointer to the i-th parameter, in crtParamSettings order
+func (p *CRTParams) field(i int) *float64 {
+	switch i {
+	case 0:
+		return &p.Curvature
+	case 1:
+		return &p.ScanlineStrength
+	case 2:
+		return &p.Aberration
+	default:
+		return &p.Flicker
+	}
+}
+
+// CRT shader with enhanced effects - FIXED with time uniform
+const crtShaderSrc = `
+package main
+
+var Time float
+var ScreenSize vec2
+var Beat float
+var Curvature float
+var ScanlineStrength float
+var Aberration float
+var Flicker float
+
+func Fragment(position vec4, texCoord vec2, color vec4) vec4 {
+	var uv vec2
+	uv = texCoord
+	
+	// Enhanced barrel distortion
+	var dc vec2
+	dc = uv - 0.5
+	dc = dc * (1.0 + dot(dc, dc) * Curvature)
+	uv = dc + 0.5
+	
+	// Check bounds
+	if uv.x < 0.0 || uv.x > 1.0 || uv.y < 0.0 || uv.y > 1.0 {
+		return vec4(0.0, 0.0, 0.0, 1.0)
+	}
+	
+	// Sample texture
+	var col vec4
+	col = imageSrc0At(uv)
+	
+	// Scanlines with varying intensity
+	var scanline float
+	scanline = sin(uv.y * 800.0 + Time * 2.0) * ScanlineStrength
+	col.rgb = col.rgb - scanline
+	
+	// RGB shift (chromatic aberration)
+	var rShift float
+	var bShift float
+	rShift = imageSrc0At(uv + vec2(Aberration, 0.0)).r
+	bShift = imageSrc0At(uv - vec2(Aberration, 0.0)).b
+	col.r = rShift
+	col.b = bShift
+	
+	// Phosphor glow
+	var glow float
+	glow = imageSrc0At(uv + vec2(0.001, 0.001)).g * 0.1
+	col.g = col.g + glow
+	
+	// Vignette effect
+	var vignette float
+	vignette = 1.0 - dot(dc, dc) * 0.7
+	col.rgb = col.rgb * vignette
+	
+	// Flickering
+	var flicker float
+	flicker = 1.0 - Flicker + sin(Time * 120.0) * (Flicker + Beat * 0.15)
+	col.rgb = col.rgb * flicker
+	
+	return col * color
+}
+`
+
+// Bloom shader adding a blurred copy of the bright pixels back onto the image
+const bloomShaderSrc = `
+//kage:unit pixels
+
+package main
+
+var Threshold float
+var Intensity float
+
+// bright returns the color at pos if its luminance exceeds the threshold
+func bright(pos vec2) vec3 {
+	var col vec4
+	col = imageSrc0At(pos)
+	
+	var luma float
+	luma = dot(col.rgb, vec3(0.299, 0.587, 0.114))
+	return col.rgb * smoothstep(Threshold, Threshold + 0.1, luma)
+}
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	var base vec4
+	base = imageSrc0At(srcPos)
+	
+	// Gaussian blur of the bright pass over a sparse 9x9 grid
+	var sum vec3
+	var total float
+	for y := -4; y <= 4; y++ {
+		for x := -4; x <= 4; x++ {
+			var w float
+			w = exp(-float(x * x + y * y) / 8.0)
+			sum += bright(srcPos + vec2(float(x), float(y)) * 2.0) * w
+			total += w
+		}
+	}
+	
+	base.rgb = base.rgb + sum / total * Intensity
+	return base * color
+}
+`
+
+// Gamma shader raising each channel of the final image to 1/Gamma, then scaling it
+// by the brightness passed as the color scale
+const gammaShaderSrc = `
+//kage:unit pixels
+
+package main
+
+var Gamma float
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	var col vec4
+	col = imageSrc0At(srcPos)
+	
+	// The frame is opaque, so there is no alpha to divide out first
+	col.rgb = pow(col.rgb, vec3(1.0 / Gamma))
+	return col * color
+}
+`
+
+// setShaderPath loads the CRT shader from an external Kage file and watches it for changes
+func (g *Game) setShaderPath(path string) {
+	g.shaderPath = path
+	g.reloadCRTShader()
+}
+
+// reloadCRTShader recompiles the CRT shader from shaderPath,
+// keeping the previous shader if the file can't be read or compiled
+func (g *Game) reloadCRTShader() {
+	info, err := os.Stat(g.shaderPath)
+	if err != nil {
+		log.Printf("Failed to stat CRT shader %s: %v", g.shaderPath, err)
+		return
+	}
+	g.shaderModTime = info.ModTime()
+
+	src, err := os.ReadFile(g.shaderPath)
+	if err != nil {
+		log.Printf("Failed to read CRT shader %s: %v", g.shaderPath, err)
+		return
+	}
+
+	shader, err := ebiten.NewShader(src)
+	if err != nil {
+		log.Printf("Failed to compile CRT shader %s, keeping the previous one: %v", g.shaderPath, err)
+		return
+	}
+
+	if g.crtShader != nil {
+		g.crtShader.Dispose()
+	}
+	g.crtShader = shader
+	log.Printf("Loaded CRT shader from %s", g.shaderPath)
+}
+
+// pollCRTShader reloads the external CRT shader when the file has been modified
+func (g *Game) pollCRTShader() {
+	if g.shaderPath == "" {
+		return
+	}
+
+	g.shaderPoll++
+	if g.shaderPoll < shaderPollInterval {
+		return
+	}
+	g.shaderPoll = 0
+
+	info, err := os.Stat(g.shaderPath)
+	if err != nil || info.ModTime().Equal(g.shaderModTime) {
+		return
+	}
+	g.reloadCRTShader()
+}
+
+// newPhosphorMask builds one aperture-grille triad: a red, a green and a blue column, each
+// dimming the two other channels by intensity, from 0 (no effect) to 1 (pure phosphors)
+func newPhosphorMask(intensity float64) *ebiten.Image {
+	img := image.NewRGBA(image.Rect(0, 0, phosphorTriad, 1))
+	dim := uint8(math.Round(255 * (1 - intensity)))
+	img.Set(0, 0, color.RGBA{255, dim, dim, 255})
+	img.Set(1, 0, color.RGBA{dim, 255, dim, 255})
+	img.Set(2, 0, color.RGBA{dim, dim, 255, 255})
+	return ebiten.NewImageFromImage(img)
+}
+
+// drawPhosphorMask multiplies the mask, repeated at one texel per screen pixel, over the
+// area of screen, so the triads stay sharp whatever the window size
+func (g *Game) drawPhosphorMask(screen *ebiten.Image, area image.Rectangle) {
+	vertices := make([]ebiten.Vertex, 4)
+	for i, c := range [4]image.Point{area.Min, {area.Max.X, area.Min.Y}, {area.Min.X, area.Max.Y}, area.Max} {
+		vertices[i] = ebiten.Vertex{
+			DstX:   float32(c.X),
+			DstY:   float32(c.Y),
+			SrcX:   float32(c.X),
+			SrcY:   float32(c.Y),
+			ColorR: 1,
+			ColorG: 1,
+			ColorB: 1,
+			ColorA: 1,
+		}
+	}
+
+	op := &ebiten.DrawTrianglesOptions{Address: ebiten.AddressRepeat, Blend: multiplyBlend}
+	screen.DrawTriangles(vertices, []uint16{0, 1, 2, 1, 3, 2}, g.phosphorMask, op)
+}
+
+// drawGammaCorrected draws the frame onto the screen through the gamma shader, with the
+// placement and color scale of frameOp
+func (g *Game) drawGammaCorrected(screen *ebiten.Image) {
+	g.drawRectOp.Images[0] = g.frame
+	g.drawRectOp.GeoM = g.frameOp.GeoM
+	g.drawRectOp.ColorScale = g.frameOp.ColorScale
+	g.drawRectOp.Uniforms = map[string]interface{}{
+		"Gamma": float32(g.cfg.Gamma),
+	}
+	screen.DrawRectShader(screenWidth, screenHeight, g.gammaShader, g.drawRectOp)
+}
+
+// applyBloom renders stCanvas with the bloom pass into bloomCanvas
+func (g *Game) applyBloom() {
+	g.drawRectOp.Images[0] = g.stCanvas
+	g.drawRectOp.GeoM.Reset()
+	g.drawRectOp.ColorScale.Reset()
+	g.drawRectOp.Uniforms = map[string]interface{}{
+		"Threshold": float32(g.bloomThreshold),
+		"Intensity": float32(g.bloomIntensity),
+	}
+
+	g.bloomCanvas.Clear()
+	g.bloomCanvas.DrawRectShader(stCanvasWidth, stCanvasHeight, g.bloomShader, g.drawRectOp)
+}
+
+// crtUniforms returns the CRT shader uniforms for the given time
+func (g *Game) crtUniforms(time float64) map[string]interface{} {
+	return map[string]interface{}{
+		"Time":             float32(time),
+		"ScreenSize":       []float32{float32(g.viewport.Dx()), float32(g.viewport.Dy())},
+		"Beat":             float32(g.beatFlash),
+		"Curvature":        float32(g.crtParams.Curvature),
+		"ScanlineStrength": float32(g.crtParams.ScanlineStrength),
+		"Aberration":       float32(g.crtParams.Aberration),
+		"Flicker":          float32(g.crtParams.Flicker),
+	}
+}
+
+// updateCRTParams selects a CRT parameter with Tab and adjusts it with Page Up / Page Down
+func (g *Game) updateCRTParams() {
+	if g.justPressed(ActionCRTParam) {
+		g.crtParam = (g.crtParam + 1) % len(crtParamSettings)
+		log.Printf("CRT %s selected", crtParamSettings[g.crtParam].name)
+	}
+
+	step := 0.0
+	if g.justPressed(ActionCRTParamUp) {
+		step = crtParamSettings[g.crtParam].step
+	}
+	if g.justPressed(ActionCRTParamDown) {
+		step = -crtParamSettings[g.crtParam].step
+	}
+	if step == 0 {
+		return
+	}
+
+	value := g.crtParams.field(g.crtParam)
+	*value = math.Max(0, math.Min(crtParamSettings[g.crtParam].max, *value+step))
+	log.Printf("CRT %s: %.3f", crtParamSettings[g.crtParam].name, *value)
+}
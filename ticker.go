@@ -0,0 +1,118 @@
+package main
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// Bottom ticker of the intro
+	tickerFontScale = 0.75
+)
+
+// Ticker feeds a text in letter by letter at a fixed position and scrolls it left.
+// Only the offset of the letter being fed is kept: the letters fed so far are redrawn
+// at their computed positions every frame, so the canvas itself is never scrolled.
+type Ticker struct {
+	runes  []rune  // Text, already converted for the font
+	feedX  int     // Where letters enter the canvas
+	scale  float64 // Font scale
+	speed  int     // Pixels per frame
+	loop   bool    // Start the text over at its end instead of finishing
+	canvas *ebiten.Image
+
+	x      int // Offset of the letter being fed from feedX
+	letter int // Index of the letter being fed, -1 before the first
+	done   bool
+}
+
+// NewTicker creates a ticker for text on a screen-wide canvas, feeding letters at feedX
+func NewTicker(text []rune, feedX int, scale float64, speed int, loop bool) *Ticker {
+	t := &Ticker{
+		runes:  text,
+		feedX:  feedX,
+		scale:  scale,
+		speed:  speed,
+		loop:   loop,
+		canvas: ebiten.NewImage(screenWidth, int(fontHeight*scale)),
+	}
+	t.Reset()
+	return t
+}
+
+// Reset starts the ticker over with an empty canvas
+func (t *Ticker) Reset() {
+	t.x = -1
+	t.letter = -1
+	t.done = false
+}
+
+// Finish ends the ticker and drops its letters, so nothing is drawn anymore
+func (t *Ticker) Finish() {
+	t.done = true
+	t.letter = -1
+}
+
+// letterAt returns the letter at index i, wrapping around the text
+func (t *Ticker) letterAt(i int) rune {
+	if len(t.runes) == 0 {
+		return ' '
+	}
+	return t.runes[i%len(t.runes)]
+}
+
+// updateTicker moves the ticker one frame left, feeding the next letter once the
+// current one has moved its whole advance in. A step longer than a narrow glyph's
+// advance uncovers several letters at once.
+func (g *Game) updateTicker(t *Ticker) {
+	if t.done {
+		return
+	}
+
+	for t.x < 0 {
+		if t.letter >= 0 {
+			t.x += int(g.glyphAdvance(t.letterAt(t.letter), t.scale))
+		}
+		t.letter++
+		if !t.loop && t.letter >= len(t.runes) {
+			t.done = true
+			return
+		}
+		if t.letter == 0 {
+			break // The first letter enters at the feed position
+		}
+	}
+	t.x -= t.speed
+}
+
+// drawTicker draws the letters fed so far into the ticker canvas: the current one at the
+// feed position and the earlier ones to its left, each one advance further back
+func (g *Game) drawTicker(t *Ticker) {
+	t.canvas.Clear()
+	if t.letter < 0 {
+		return
+	}
+
+	x := t.feedX + t.x
+	for i := t.letter; i >= 0; i-- {
+		// The letter after the last one, once the text is complete, has no glyph
+		if t.loop || i < len(t.runes) {
+			char := t.letterAt(i)
+			if x+int(g.glyphAdvance(char, t.scale)) <= 0 {
+				break
+			}
+			if letter := g.glyph(char); letter != nil {
+				srcRect := image.Rect(letter.x, letter.y, letter.x+letter.width, letter.y+fontHeight)
+				g.drawOp.GeoM.Reset()
+				g.drawOp.ColorScale.Reset()
+				g.drawOp.GeoM.Scale(t.scale, t.scale)
+				g.drawOp.GeoM.Translate(float64(x), 0)
+				t.canvas.DrawImage(g.fontImg.SubImage(srcRect).(*ebiten.Image), g.drawOp)
+			}
+		}
+		if i > 0 {
+			x -= int(g.glyphAdvance(t.letterAt(i-1), t.scale))
+		}
+	}
+}
@@ -0,0 +1,886 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/olivierh59500/ym-player/pkg/lzh"
+	"github.com/olivierh59500/ym-player/pkg/stsound"
+)
+
+const (
+	// Audio parameters
+	defaultSampleRate = 44100
+	ymNativeRate      = 44100  // Rate the YM engine renders at before resampling
+	stereoCrossover   = 1000.0 // Hz above which the pseudo-stereo leans left
+
+	// Fallback tone parameters, used when the YM tune can't be loaded
+	toneFrequency = 220.0 // Pitch of the tone in Hz
+	toneLevel     = 0.15  // Peak amplitude, kept soft
+	toneBPM       = 120   // Pulses per minute, giving the music sync a steady beat
+	toneDecay     = 6.0   // Decay rate of each pulse per second
+	toneLoopBeats = 32    // Pulses per loop, for the progress bar
+
+	// YM file layout
+	ymRegisters     = 14 // PSG registers stored per frame in YM2 and YM3 files
+	ymHeaderSize    = 34 // Fixed part of the YM5 and YM6 header
+	lhaMethodOffset = 2  // Position of the compression method in an LHA header
+	lhaPackedOffset = 7  // Position of the packed size in an LHA header
+)
+
+// YMPlayer wraps the YM player for Ebiten audio
+type YMPlayer struct {
+	player       *stsound.StSound
+	data         []byte // Tune data, kept to reload a tune the engine can't seek
+	sampleRate   int
+	nativeRate   int
+	buffer       []int16
+	mutex        sync.Mutex
+	position     int64
+	totalSamples int64
+	loop         bool
+	loopLimit    int
+	loopCount    int
+	enginePos    uint32 // Engine position in ms after the last chunk, going back when the tune loops
+	volume       float64
+	muted        bool // Output silence while the tune keeps playing
+	info         YMInfo
+
+	// Envelope follower state
+	envelope    float64
+	average     float64
+	attackCoef  float64
+	releaseCoef float64
+	averageCoef float64
+	beat        bool
+	beatArmed   bool
+
+	// Low-pass filter state, kept across Read calls to avoid clicks
+	lowPass      bool
+	lowPassCoef  float64
+	lowPassState float64
+
+	// Pseudo-stereo state. stsound mixes the three PSG channels into one, so they
+	// can't be panned; instead the highs lean left and the lows right.
+	width      float64
+	splitCoef  float64
+	splitState float64
+
+	// Resampler state, kept across Read calls to avoid clicks
+	native     []int16
+	nativePos  int
+	prevSample int16
+	nextSample int16
+	phase      float64
+	step       float64
+
+	// End of track notification
+	onEnd func()
+	ended bool
+
+	err error // Failure that stopped the tune, reported by Err
+}
+
+// errPlayerClosed is reported by YMPlayer.Err and Seek when the engine is gone, after Close or a failed reload
+var errPlayerClosed = errors.New("YM player is closed")
+
+// YMInfo holds the metadata of the loaded YM tune
+type YMInfo struct {
+	Name       string
+	Author     string
+	DurationMs int64
+	LoopMs     int64 // Where a looping tune starts again after its end
+}
+
+// NewYMPlayer creates a new YM player instance
+func NewYMPlayer(data []byte, sampleRate int, loop bool) (*YMPlayer, error) {
+	if err := checkYMFormat(data); err != nil {
+		return nil, err
+	}
+
+	player, err := loadTune(data, ymNativeRate, loop)
+	if err != nil {
+		return nil, err
+	}
+
+	info := player.GetInfo()
+	loopMs := loopStartMs(data)
+	if loopMs >= int64(info.MusicTimeInMs) {
+		loopMs = 0
+	}
+	totalSamples := int64(info.MusicTimeInMs) * int64(sampleRate) / 1000
+
+	y := &YMPlayer{
+		player:       player,
+		data:         data,
+		sampleRate:   sampleRate,
+		nativeRate:   ymNativeRate,
+		buffer:       make([]int16, 4096),
+		totalSamples: totalSamples,
+		loop:         loop,
+		volume:       1.0,
+		info: YMInfo{
+			Name:       metadataOrUnknown(info.SongName),
+			Author:     metadataOrUnknown(info.SongAuthor),
+			DurationMs: int64(info.MusicTimeInMs),
+			LoopMs:     loopMs,
+		},
+		attackCoef:  envelopeCoef(envelopeAttack, sampleRate),
+		releaseCoef: envelopeCoef(envelopeRelease, sampleRate),
+		averageCoef: envelopeCoef(envelopeAverage, sampleRate),
+		native:      make([]int16, 1024),
+		step:        float64(ymNativeRate) / float64(sampleRate),
+		splitCoef:   1 - math.Exp(-2*math.Pi*stereoCrossover/float64(sampleRate)),
+	}
+	y.resetPlayback()
+	return y, nil
+}
+
+// loadTune creates an engine rendering at rate and loads the tune into it. stsound panics
+// on some corrupt archives instead of returning an error, so the panic is turned into one,
+// releasing the engine as for any other failure.
+func loadTune(data []byte, rate int, loop bool) (player *stsound.StSound, err error) {
+	engine := stsound.CreateWithRate(rate)
+	defer func() {
+		if r := recover(); r != nil {
+			engine.Destroy()
+			player, err = nil, fmt.Errorf("failed to load YM data, it may be corrupt: %v", r)
+		}
+	}()
+
+	if err := engine.LoadMemory(data); err != nil {
+		engine.Destroy()
+		return nil, fmt.Errorf("failed to load YM data: %w", err)
+	}
+	engine.SetLoopMode(loop)
+	return engine, nil
+}
+
+// checkYMFormat reports tune data that stsound can't play as an error, instead of letting
+// it load into garbled audio. Raw YM2! to YM6! files are checked for a consistent header;
+// LHA archives are accepted with the -lh5- method stsound unpacks and the whole packed
+// tune present, the tune inside being checked when stsound loads it.
+func checkYMFormat(data []byte) error {
+	if isLHA(data) {
+		if method := string(data[lhaMethodOffset : lhaMethodOffset+5]); method != "-lh5-" {
+			return fmt.Errorf("unsupported LHA compression %s, only -lh5- is supported", method)
+		}
+		// The packed tune follows the header, whose size excludes its first two bytes
+		if len(data) < lhaPackedOffset+4 {
+			return fmt.Errorf("LHA header truncated (%d bytes)", len(data))
+		}
+		packed := int64(binary.LittleEndian.Uint32(data[lhaPackedOffset:]))
+		if size := int64(data[0]) + 2 + packed; size > int64(len(data)) {
+			return fmt.Errorf("LHA archive truncated: %d of %d bytes", len(data), size)
+		}
+		return nil
+	}
+
+	if len(data) < 4 {
+		return fmt.Errorf("YM data too short (%d bytes)", len(data))
+	}
+	switch id := string(data[:4]); id {
+	case "YM2!", "YM3!":
+		// Interleaved register dumps, one column of frames per register
+		if (len(data)-4)%ymRegisters != 0 {
+			return fmt.Errorf("%s data of %d bytes isn't a whole number of frames", id, len(data))
+		}
+	case "YM3b":
+		// YM3 followed by the 32-bit loop frame
+		if len(data) < 8 || (len(data)-8)%ymRegisters != 0 {
+			return fmt.Errorf("%s data of %d bytes isn't a whole number of frames", id, len(data))
+		}
+	case "YM4!", "YM5!", "YM6!":
+		if len(data) < 12 || string(data[4:12]) != "LeOnArD!" {
+			return fmt.Errorf("%s header lacks the LeOnArD! signature", id)
+		}
+		if id == "YM4!" {
+			break
+		}
+		// YM5 and YM6 give the PSG clock and the frame rate the registers are played at
+		if len(data) < ymHeaderSize {
+			return fmt.Errorf("%s header truncated (%d bytes)", id, len(data))
+		}
+		frames := binary.BigEndian.Uint32(data[12:16])
+		clock := binary.BigEndian.Uint32(data[22:26])
+		rate := binary.BigEndian.Uint16(data[26:28])
+		if frames == 0 || clock == 0 || rate == 0 {
+			return fmt.Errorf("%s header invalid: %d frames, %d Hz clock, %d Hz frame rate", id, frames, clock, rate)
+		}
+	default:
+		return fmt.Errorf("unsupported YM format %q, only YM2! to YM6! files and -lh5- archives are supported", id)
+	}
+	return nil
+}
+
+// isLHA reports whether data is an LHA archive, going by the compression method in its header
+func isLHA(data []byte) bool {
+	return len(data) > lhaMethodOffset+5 && string(data[lhaMethodOffset:lhaMethodOffset+3]) == "-lh" && data[lhaMethodOffset+4] == '-'
+}
+
+// loopStartMs returns where a looping tune starts again, from the loop frame in its
+// header, which stsound doesn't report. YM2 and YM3 tunes loop from the start. Call it
+// on data stsound has loaded, so a packed tune is known to unpack.
+func loopStartMs(data []byte) int64 {
+	if isLHA(data) {
+		depacked, err := lzh.Decompress(data)
+		if err != nil {
+			return 0
+		}
+		data = depacked
+	}
+	if len(data) < 4 {
+		return 0
+	}
+
+	var frame, rate int64
+	switch string(data[:4]) {
+	case "YM3b":
+		// YM3 tunes play at 50 Hz
+		frame, rate = int64(binary.LittleEndian.Uint32(data[len(data)-4:])), 50
+	case "YM5!", "YM6!":
+		if len(data) < ymHeaderSize {
+			return 0
+		}
+		frame, rate = int64(binary.BigEndian.Uint32(data[28:32])), int64(binary.BigEndian.Uint16(data[26:28]))
+	}
+	if rate == 0 {
+		return 0
+	}
+	return frame * 1000 / rate
+}
+
+// resetPlayback clears the playback, resampler and envelope state for a fresh start of the tune
+func (y *YMPlayer) resetPlayback() {
+	y.position = 0
+	y.enginePos = 0
+	y.loopCount = 0
+	y.ended = false
+	y.envelope = 0
+	y.average = 0
+	y.beat = false
+	y.beatArmed = true
+	y.lowPassState = 0
+	y.splitState = 0
+	y.nativePos = len(y.native)
+	y.prevSample = 0
+	y.nextSample = 0
+	y.phase = 2 // Primes both interpolation samples on the first render
+}
+
+// envelopeCoef returns the per-sample smoothing coefficient for a time constant
+func envelopeCoef(seconds float64, sampleRate int) float64 {
+	return 1 - math.Exp(-1/(seconds*float64(sampleRate)))
+}
+
+// loopOffset brings pos, which runs on past the end of a looping tune, back within one
+// play of it: past length, the tune plays on from loopStart. A length of 0, for an
+// unknown length, leaves pos as is.
+func loopOffset(pos, length, loopStart int64) int64 {
+	if length <= 0 || pos < length {
+		return pos
+	}
+	return loopStart + (pos-loopStart)%(length-loopStart)
+}
+
+// metadataOrUnknown returns the trimmed metadata string, or "UNKNOWN" when empty
+func metadataOrUnknown(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "UNKNOWN"
+	}
+	return s
+}
+
+// Progress returns how far playback is through the tune, from 0 to 1, starting over on each loop
+func (y *YMPlayer) Progress() float64 {
+	y.mutex.Lock()
+	defer y.mutex.Unlock()
+
+	if y.totalSamples <= 0 {
+		return 0
+	}
+	return float64(loopOffset(y.position, y.totalSamples, y.loopStartSamples())) / float64(y.totalSamples)
+}
+
+// Info returns the name, author and duration of the loaded tune
+func (y *YMPlayer) Info() YMInfo {
+	return y.info
+}
+
+// SetOnEnd registers a callback invoked once when the tune reaches its end, or a looping
+// tune its loop limit.
+// The callback runs on the audio goroutine, outside the player lock.
+func (y *YMPlayer) SetOnEnd(fn func()) {
+	y.mutex.Lock()
+	defer y.mutex.Unlock()
+
+	y.onEnd = fn
+}
+
+// SetLowPass enables or disables the one-pole low-pass filter on the output, at the given cutoff in Hz
+func (y *YMPlayer) SetLowPass(enabled bool, cutoff float64) {
+	y.mutex.Lock()
+	defer y.mutex.Unlock()
+
+	y.lowPass = enabled
+	y.lowPassCoef = 1 - math.Exp(-2*math.Pi*cutoff/float64(y.sampleRate))
+}
+
+// SetMuted silences the output or brings it back. The tune, and the envelope follower
+// driving the music sync, keep running while muted.
+func (y *YMPlayer) SetMuted(muted bool) {
+	y.mutex.Lock()
+	defer y.mutex.Unlock()
+
+	y.muted = muted
+}
+
+// SetStereoWidth sets the pseudo-stereo spread, from 0 (mono) to 1
+func (y *YMPlayer) SetStereoWidth(width float64) {
+	y.mutex.Lock()
+	defer y.mutex.Unlock()
+
+	y.width = width
+}
+
+// SetLoopLimit makes a looping tune stop after it has played n times (0 loops forever)
+func (y *YMPlayer) SetLoopLimit(n int) {
+	y.mutex.Lock()
+	defer y.mutex.Unlock()
+
+	y.loopLimit = n
+}
+
+// Read implements io.Reader for audio streaming. Ebiten stops the whole game on a stream
+// error, so a failure ends the stream like the end of the tune and is reported by Err.
+func (y *YMPlayer) Read(p []byte) (int, error) {
+	n, onEnd, err := y.read(p)
+	if onEnd != nil {
+		onEnd()
+	}
+	if err != nil && err != io.EOF {
+		return n, io.EOF
+	}
+	return n, err
+}
+
+// Err returns the failure that stopped the tune, or nil while it plays or once it has ended normally
+func (y *YMPlayer) Err() error {
+	y.mutex.Lock()
+	defer y.mutex.Unlock()
+
+	return y.err
+}
+
+// read fills p under the player lock and returns the end callback to run, if the tune just ended.
+// A failure, including a panic of the engine on corrupt data, is kept for Err.
+func (y *YMPlayer) read(p []byte) (n int, onEnd func(), err error) {
+	y.mutex.Lock()
+	defer y.mutex.Unlock()
+	defer func() {
+		if r := recover(); r != nil {
+			n, onEnd, err = 0, nil, fmt.Errorf("YM engine failed, the tune data may be corrupt: %v", r)
+		}
+		if err != nil && err != io.EOF && y.err == nil {
+			y.err = err
+		}
+	}()
+
+	// A failed reload in Seek leaves no engine to render with
+	if y.player == nil {
+		return 0, nil, errPlayerClosed
+	}
+
+	samplesNeeded := len(p) / 4
+	outBuffer := make([]int16, samplesNeeded*2)
+
+	processed := 0
+	for processed < samplesNeeded {
+		chunkSize := samplesNeeded - processed
+		if chunkSize > len(y.buffer) {
+			chunkSize = len(y.buffer)
+		}
+
+		// The engine only reports the end of a tune that doesn't loop. A looping tune
+		// jumps back to its loop frame instead, which shows as its position going back;
+		// the chunk that completes the last loop already holds the start of the next one.
+		if !y.render(y.buffer[:chunkSize]) || y.loopEnded() {
+			err = io.EOF
+			for i := processed * 2; i < len(outBuffer); i++ {
+				outBuffer[i] = 0
+			}
+			break
+		}
+
+		for i := 0; i < chunkSize; i++ {
+			y.followEnvelope(y.buffer[i])
+
+			// The filter runs even when disabled so toggling it doesn't jump
+			raw := float64(y.buffer[i])
+			y.lowPassState += (raw - y.lowPassState) * y.lowPassCoef
+			if y.lowPass {
+				raw = y.lowPassState
+			}
+			// Split at the crossover and move the highs between the channels;
+			// the left and right channels still sum to the mono signal
+			y.splitState += (raw - y.splitState) * y.splitCoef
+			side := (raw - y.splitState) * y.width
+			if y.muted {
+				outBuffer[(processed+i)*2] = 0
+				outBuffer[(processed+i)*2+1] = 0
+				continue
+			}
+			outBuffer[(processed+i)*2] = clampSample((raw + side) * y.volume)
+			outBuffer[(processed+i)*2+1] = clampSample((raw - side) * y.volume)
+		}
+		y.detectBeat()
+
+		processed += chunkSize
+		y.position += int64(chunkSize)
+	}
+
+	buf := make([]byte, 0, len(outBuffer)*2)
+	for _, sample := range outBuffer {
+		buf = append(buf, byte(sample), byte(sample>>8))
+	}
+
+	copy(p, buf)
+	n = len(buf)
+	if n > len(p) {
+		n = len(p)
+	}
+
+	// Notify only the first time the end is reached, not on later zero-filled reads
+	if err == io.EOF && !y.ended {
+		y.ended = true
+		onEnd = y.onEnd
+	}
+
+	return n, onEnd, err
+}
+
+// loopEnded counts the loops of a looping tune after each chunk and reports whether the
+// loop limit has been reached
+func (y *YMPlayer) loopEnded() bool {
+	if !y.loop {
+		return false
+	}
+	pos := y.player.GetPos()
+	if pos < y.enginePos {
+		y.loopCount++
+	}
+	y.enginePos = pos
+	return y.loopLimit > 0 && y.loopCount >= y.loopLimit
+}
+
+// render fills dst at the output rate, linearly resampling from the engine rate when they differ.
+// It returns false when the engine reported the end of the tune.
+func (y *YMPlayer) render(dst []int16) bool {
+	if y.nativeRate == y.sampleRate {
+		return y.player.Compute(dst, len(dst))
+	}
+
+	ok := true
+	for i := range dst {
+		for y.phase >= 1 {
+			y.phase--
+			y.prevSample = y.nextSample
+			if y.nativePos >= len(y.native) {
+				if !y.player.Compute(y.native, len(y.native)) {
+					ok = false
+				}
+				y.nativePos = 0
+			}
+			y.nextSample = y.native[y.nativePos]
+			y.nativePos++
+		}
+
+		prev := float64(y.prevSample)
+		dst[i] = int16(prev + (float64(y.nextSample)-prev)*y.phase)
+		y.phase += y.step
+	}
+	return ok
+}
+
+// clampSample converts v to a 16-bit sample, saturating instead of wrapping around
+func clampSample(v float64) int16 {
+	return int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, v)))
+}
+
+// followEnvelope feeds one raw sample into the envelope follower
+func (y *YMPlayer) followEnvelope(sample int16) {
+	level := math.Abs(float64(sample)) / 32768
+	if level > y.envelope {
+		y.envelope += (level - y.envelope) * y.attackCoef
+	} else {
+		y.envelope += (level - y.envelope) * y.releaseCoef
+	}
+	y.average += (y.envelope - y.average) * y.averageCoef
+}
+
+// detectBeat latches a beat when the envelope rises well above its average
+func (y *YMPlayer) detectBeat() {
+	if y.beatArmed && y.envelope > beatMinEnergy && y.envelope > y.average*beatThreshold {
+		y.beat = true
+		y.beatArmed = false
+	} else if y.envelope < y.average {
+		y.beatArmed = true
+	}
+}
+
+// Energy returns the smoothed short-term loudness of the tune (0 to 1)
+func (y *YMPlayer) Energy() float64 {
+	y.mutex.Lock()
+	defer y.mutex.Unlock()
+
+	return y.envelope
+}
+
+// Beat reports whether a beat was detected since the last call
+func (y *YMPlayer) Beat() bool {
+	y.mutex.Lock()
+	defer y.mutex.Unlock()
+
+	beat := y.beat
+	y.beat = false
+	return beat
+}
+
+// Seek implements io.Seeker on the 16-bit stereo output stream.
+// The engine can't seek, so the tune is reloaded and rendered silently up to the target.
+func (y *YMPlayer) Seek(offset int64, whence int) (int64, error) {
+	y.mutex.Lock()
+	defer y.mutex.Unlock()
+
+	const frameSize = 4 // Bytes per stereo 16-bit sample
+	var target int64
+	switch whence {
+	case io.SeekStart:
+		target = offset / frameSize
+	case io.SeekCurrent:
+		target = y.position + offset/frameSize
+	case io.SeekEnd:
+		target = y.totalSamples + offset/frameSize
+	default:
+		return 0, fmt.Errorf("invalid whence %d", whence)
+	}
+	if target < 0 {
+		return 0, fmt.Errorf("negative seek position %d", target)
+	}
+
+	if y.player == nil {
+		return 0, errPlayerClosed
+	}
+	reload := !y.player.IsSeekable() || y.info.DurationMs <= 0
+	if err := y.seekTo(target, reload); err != nil {
+		return 0, err
+	}
+	return y.position * frameSize, nil
+}
+
+// seekTo moves playback to the sample position target. Past the end, a looping tune
+// goes on from its loop start with the loops played counted toward the loop limit, and
+// a tune that doesn't loop ends. The engine jumps straight there or, with reload, for
+// tunes it can't seek, the tune is reloaded and rendered forward.
+func (y *YMPlayer) seekTo(target int64, reload bool) error {
+	offset, loops := target, 0
+	if y.totalSamples > 0 && target >= y.totalSamples {
+		if y.loop {
+			loopStart := y.loopStartSamples()
+			loops = 1 + int((target-y.totalSamples)/(y.totalSamples-loopStart))
+			offset = loopOffset(target, y.totalSamples, loopStart)
+		} else {
+			offset = y.totalSamples
+		}
+	}
+
+	if reload {
+		y.player.Destroy()
+		player, err := loadTune(y.data, ymNativeRate, y.loop)
+		if err != nil {
+			y.player = nil
+			return fmt.Errorf("failed to reload YM data: %w", err)
+		}
+		y.player = player
+		y.resetPlayback()
+
+		for rendered := int64(0); rendered < offset; {
+			chunk := min(offset-rendered, int64(len(y.buffer)))
+			if !y.render(y.buffer[:chunk]) {
+				break
+			}
+			rendered += chunk
+		}
+	} else {
+		// Past the end of a tune that doesn't loop, play the last moment so it ends on
+		// the next read, since the engine takes the end for the start
+		ms := min(offset*1000/int64(y.sampleRate), y.info.DurationMs-1)
+		y.resetPlayback()
+		y.player.Restart() // Clears the end of a tune that has played out
+		y.player.Seek(uint32(ms))
+	}
+
+	y.position = target
+	y.loopCount = loops
+	y.enginePos = y.player.GetPos()
+	return nil
+}
+
+// loopStartSamples returns where a looping tune starts again, in output samples
+func (y *YMPlayer) loopStartSamples() int64 {
+	return y.info.LoopMs * int64(y.sampleRate) / 1000
+}
+
+// Close releases resources
+func (y *YMPlayer) Close() error {
+	y.mutex.Lock()
+	defer y.mutex.Unlock()
+
+	if y.player != nil {
+		y.player.Destroy()
+		y.player = nil
+	}
+	return nil
+}
+
+// MusicSource is the stream played by the demo, with what the music sync and credits read from it
+type MusicSource interface {
+	io.ReadSeekCloser
+	Info() YMInfo
+	Energy() float64
+	Beat() bool
+	Progress() float64
+	SetMuted(muted bool)
+	Err() error
+}
+
+// ToneGenerator plays a soft pulsing tone in the YM player's 16-bit stereo format,
+// standing in for the tune when it fails to load so the music-reactive effects still move
+type ToneGenerator struct {
+	mutex      sync.Mutex
+	sampleRate int
+	position   int64 // Frames played since the start of the loop
+	beatLength int64 // Frames per pulse
+	envelope   float64
+	beat       bool
+	muted      bool
+}
+
+// NewToneGenerator creates a fallback tone generator at the given sample rate
+func NewToneGenerator(sampleRate int) *ToneGenerator {
+	return &ToneGenerator{
+		sampleRate: sampleRate,
+		beatLength: int64(sampleRate) * 60 / toneBPM,
+	}
+}
+
+// loopLength returns the number of frames in one loop of the tone
+func (t *ToneGenerator) loopLength() int64 {
+	return t.beatLength * toneLoopBeats
+}
+
+// Read implements io.Reader, never reaching the end of the stream
+func (t *ToneGenerator) Read(p []byte) (int, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	frames := len(p) / 4
+	for i := 0; i < frames; i++ {
+		inBeat := t.position % t.beatLength
+		if inBeat == 0 {
+			t.beat = true
+		}
+		seconds := float64(t.position) / float64(t.sampleRate)
+		t.envelope = toneLevel * math.Exp(-float64(inBeat)/float64(t.sampleRate)*toneDecay)
+		sample := int16(t.envelope * 32767 * math.Sin(2*math.Pi*toneFrequency*seconds))
+		if t.muted {
+			sample = 0
+		}
+
+		p[i*4] = byte(sample)
+		p[i*4+1] = byte(sample >> 8)
+		p[i*4+2] = byte(sample)
+		p[i*4+3] = byte(sample >> 8)
+
+		t.position = (t.position + 1) % t.loopLength()
+	}
+	return frames * 4, nil
+}
+
+// Seek implements io.Seeker on the 16-bit stereo output stream, wrapping into the loop
+func (t *ToneGenerator) Seek(offset int64, whence int) (int64, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	const frameSize = 4 // Bytes per stereo 16-bit sample
+	var target int64
+	switch whence {
+	case io.SeekStart:
+		target = offset / frameSize
+	case io.SeekCurrent:
+		target = t.position + offset/frameSize
+	case io.SeekEnd:
+		target = t.loopLength() + offset/frameSize
+	default:
+		return 0, fmt.Errorf("invalid whence %d", whence)
+	}
+	if target < 0 {
+		return 0, fmt.Errorf("negative seek position %d", target)
+	}
+
+	t.position = target % t.loopLength()
+	t.envelope = 0
+	t.beat = false
+	return t.position * frameSize, nil
+}
+
+// Close implements io.Closer; the generator holds no resources
+func (t *ToneGenerator) Close() error {
+	return nil
+}
+
+// SetMuted silences the tone or brings it back, the pulses still driving the music sync
+func (t *ToneGenerator) SetMuted(muted bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.muted = muted
+}
+
+// Err implements MusicSource; the tone can't fail
+func (t *ToneGenerator) Err() error {
+	return nil
+}
+
+// Info describes the fallback tone for the music credits
+func (t *ToneGenerator) Info() YMInfo {
+	return YMInfo{
+		Name:       "FALLBACK TONE",
+		Author:     "TEAMG1",
+		DurationMs: t.loopLength() * 1000 / int64(t.sampleRate),
+	}
+}
+
+// Energy returns the current loudness of the pulse (0 to 1)
+func (t *ToneGenerator) Energy() float64 {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.envelope
+}
+
+// Beat reports whether a pulse started since the last call
+func (t *ToneGenerator) Beat() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	beat := t.beat
+	t.beat = false
+	return beat
+}
+
+// Progress returns how far playback is through the loop, from 0 to 1
+func (t *ToneGenerator) Progress() float64 {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return float64(t.position) / float64(t.loopLength())
+}
+
+// ExportWAV renders the whole YM tune once and writes it as a 16-bit stereo PCM WAV file.
+// On failure the file is removed, so no truncated WAV is left behind.
+func ExportWAV(ymData []byte, sampleRate int, path string) error {
+	if err := checkYMFormat(ymData); err != nil {
+		return err
+	}
+
+	player, err := loadTune(ymData, sampleRate, false)
+	if err != nil {
+		return err
+	}
+	defer player.Destroy()
+
+	info := player.GetInfo()
+	totalSamples := int64(info.MusicTimeInMs) * int64(sampleRate) / 1000
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create WAV file: %w", err)
+	}
+	err = writeWAV(file, player, sampleRate, totalSamples)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write WAV file: %w", closeErr)
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+// writeWAV writes the WAV header and the first totalSamples samples rendered by player to dst
+func writeWAV(dst io.Writer, player *stsound.StSound, sampleRate int, totalSamples int64) error {
+	w := bufio.NewWriter(dst)
+
+	// RIFF/WAVE header for 16-bit stereo PCM
+	dataSize := uint32(totalSamples * 4)
+	header := []interface{}{
+		[4]byte{'R', 'I', 'F', 'F'},
+		uint32(36 + dataSize),
+		[4]byte{'W', 'A', 'V', 'E'},
+		[4]byte{'f', 'm', 't', ' '},
+		uint32(16),             // fmt chunk size
+		uint16(1),              // PCM
+		uint16(2),              // Channels
+		uint32(sampleRate),     // Sample rate
+		uint32(sampleRate * 4), // Byte rate
+		uint16(4),              // Block align
+		uint16(16),             // Bits per sample
+		[4]byte{'d', 'a', 't', 'a'},
+		dataSize,
+	}
+	for _, field := range header {
+		if err := binary.Write(w, binary.LittleEndian, field); err != nil {
+			return fmt.Errorf("failed to write WAV header: %w", err)
+		}
+	}
+
+	// Compute the tune and write each mono sample to both channels
+	buffer := make([]int16, 4096)
+	frame := make([]byte, 4)
+	for written := int64(0); written < totalSamples; {
+		chunkSize := len(buffer)
+		if remaining := totalSamples - written; remaining < int64(chunkSize) {
+			chunkSize = int(remaining)
+		}
+
+		// Past the end of the tune the buffer is padded with silence
+		if !player.Compute(buffer[:chunkSize], chunkSize) {
+			for i := 0; i < chunkSize; i++ {
+				buffer[i] = 0
+			}
+		}
+
+		for _, sample := range buffer[:chunkSize] {
+			binary.LittleEndian.PutUint16(frame[0:], uint16(sample))
+			binary.LittleEndian.PutUint16(frame[2:], uint16(sample))
+			if _, err := w.Write(frame); err != nil {
+				return fmt.Errorf("failed to write WAV data: %w", err)
+			}
+		}
+		written += int64(chunkSize)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write WAV data: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+// ymHeader returns a YM5 or YM6 header with the given frame count, PSG clock and frame rate
+func ymHeader(id string, frames, clock uint32, rate uint16) []byte {
+	data := make([]byte, ymHeaderSize)
+	copy(data, id+"LeOnArD!")
+	binary.BigEndian.PutUint32(data[12:], frames)
+	binary.BigEndian.PutUint32(data[22:], clock)
+	binary.BigEndian.PutUint16(data[26:], rate)
+	return data
+}
+
+func TestCheckYMFormat(t *testing.T) {
+	ym3 := append([]byte("YM3!"), make([]byte, 2*ymRegisters)...)
+
+	tests := []struct {
+		name string
+		data []byte
+		err  string // Part of the expected error, empty for none
+	}{
+		{"embedded tune", musicData, ""},
+		{"YM3", ym3, ""},
+		{"YM3 partial frame", ym3[:len(ym3)-1], "whole number of frames"},
+		{"YM3b", append(append([]byte("YM3b"), make([]byte, ymRegisters)...), 0, 0, 0, 0), ""},
+		{"YM6 Atari ST", ymHeader("YM6!", 1500, 2000000, 50), ""},
+		{"YM5 Amstrad CPC", ymHeader("YM5!", 1500, 1000000, 50), ""},
+		{"YM6 no frame rate", ymHeader("YM6!", 1500, 2000000, 0), "header invalid"},
+		{"YM6 truncated", ymHeader("YM6!", 1500, 2000000, 50)[:20], "truncated"},
+		{"YM6 bad signature", append([]byte("YM6!LEONARD!"), make([]byte, 22)...), "signature"},
+		{"LHA lh1", []byte("\x19\x00-lh1-rest of header"), "-lh1-"},
+		{"LHA truncated", musicData[:len(musicData)/2], "truncated"},
+		{"LHA header truncated", musicData[:9], "truncated"},
+		{"WAV", []byte("RIFF\x00\x00\x00\x00WAVE"), "unsupported YM format"},
+		{"empty", nil, "too short"},
+	}
+	for _, test := range tests {
+		err := checkYMFormat(test.data)
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", test.name, err)
+		case test.err != "" && err == nil:
+			t.Errorf("%s: no error, want one mentioning %q", test.name, test.err)
+		case test.err != "" && !strings.Contains(err.Error(), test.err):
+			t.Errorf("%s: error %q doesn't mention %q", test.name, err, test.err)
+		}
+	}
+}
+
+// ym6Tune returns a raw YM6 tune of the given number of 50 Hz frames, holding a steady
+// tone on channel A. Without the interleaved attribute, the 16 registers of each frame
+// follow each other.
+func ym6Tune(frames int) []byte {
+	data := ymHeader("YM6!", uint32(frames), 2000000, 50)
+	data = append(data, 0, 0, 0) // Empty song name, author and comment
+	for i := 0; i < frames; i++ {
+		regs := make([]byte, 16)
+		regs[0] = 0x1c  // Channel A period
+		regs[7] = 0x3e  // Mixer: tone on channel A only
+		regs[8] = 15    // Channel A volume
+		regs[13] = 0xff // Envelope shape left alone
+		data = append(data, regs...)
+	}
+	return append(data, "End!"...)
+}
+
+// TestTunesPlay checks that real tunes load and play audio
+func TestTunesPlay(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"YM3", ym3Tune(50)},
+		{"YM6", ym6Tune(50)},
+		{"embedded YM6 in LHA", musicData},
+	}
+	for _, test := range tests {
+		if err := checkYMFormat(test.data); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		y, err := NewYMPlayer(test.data, defaultSampleRate, false)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		buf := make([]byte, 4*defaultSampleRate/2)
+		if _, err := io.ReadFull(y, buf); err != nil {
+			t.Errorf("%s: Read failed: %v", test.name, err)
+		}
+		peak := 0
+		for i := 0; i < len(buf); i += 2 {
+			if s := int(int16(binary.LittleEndian.Uint16(buf[i:]))); s > peak {
+				peak = s
+			}
+		}
+		if peak == 0 {
+			t.Errorf("%s: played silence", test.name)
+		}
+		y.Close()
+	}
+}
+
+// TestSeek checks that seeks move the engine to the target, the embedded tune playing on
+// past its end from its loop frame 32 at 50 Hz
+func TestSeek(t *testing.T) {
+	y, err := NewYMPlayer(musicData, defaultSampleRate, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer y.Close()
+
+	info := y.Info()
+	if info.LoopMs != 640 {
+		t.Errorf("loop start %d ms, want 640 ms", info.LoopMs)
+	}
+	tests := []struct{ target, want int64 }{ // ms
+		{150000, 150000},
+		{0, 0},
+		{info.DurationMs, 640},
+		{info.DurationMs + 1000, 1640},
+	}
+	for _, test := range tests {
+		offset := 4 * test.target * defaultSampleRate / 1000
+		if pos, err := y.Seek(offset, io.SeekStart); err != nil || pos != offset {
+			t.Errorf("Seek to %d ms = %d, %v, want %d", test.target, pos, err, offset)
+		}
+		if pos := int64(y.player.GetPos()); pos != test.want {
+			t.Errorf("Seek to %d ms put the engine at %d ms, want %d", test.target, pos, test.want)
+		}
+	}
+}
+
+// TestSeekPastEnd checks that seeking past the end of a tune, through the engine and by
+// reloading a tune the engine can't seek, counts the loops played toward the loop limit
+// and ends a tune that doesn't loop
+func TestSeekPastEnd(t *testing.T) {
+	for _, reload := range []bool{false, true} {
+		y, err := NewYMPlayer(ym3Tune(50), defaultSampleRate, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ended := false
+		y.SetLoopLimit(2)
+		y.SetOnEnd(func() { ended = true })
+
+		// Halfway through the second play, leaving half a play before the end
+		target := y.totalSamples * 3 / 2
+		if err := y.seekTo(target, reload); err != nil {
+			t.Fatalf("reload %v: %v", reload, err)
+		}
+		if y.position != target || y.loopCount != 1 {
+			t.Errorf("reload %v: position %d after %d loops, want %d after 1", reload, y.position, y.loopCount, target)
+		}
+		want := y.totalSamples / 2
+		samples := readToEnd(t, y, 4*y.totalSamples)
+		if tolerance := int64(len(y.buffer) + 1024); samples < want-tolerance || samples > want+tolerance {
+			t.Errorf("reload %v: %d samples before the end, want %d ± %d", reload, samples, want, tolerance)
+		}
+		if !ended {
+			t.Errorf("reload %v: the end callback didn't run", reload)
+		}
+		y.Close()
+
+		y, err = NewYMPlayer(ym3Tune(50), defaultSampleRate, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := y.seekTo(y.totalSamples+1000, reload); err != nil {
+			t.Fatalf("reload %v: %v", reload, err)
+		}
+		if samples := readToEnd(t, y, y.totalSamples); samples > 4096 {
+			t.Errorf("reload %v: %d samples past the end of a tune that doesn't loop", reload, samples)
+		}
+		y.Close()
+	}
+}
+
+// TestSeekClosed checks that seeking a closed player reports errPlayerClosed
+func TestSeekClosed(t *testing.T) {
+	y, err := NewYMPlayer(ym3Tune(50), defaultSampleRate, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y.Close()
+	if _, err := y.Seek(0, io.SeekStart); !errors.Is(err, errPlayerClosed) {
+		t.Errorf("Seek on a closed player = %v, want %v", err, errPlayerClosed)
+	}
+}
+
+// failingWriter fails every write, like a full disk
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("no space left on device")
+}
+
+// TestExportWAV checks that the exported file holds the whole tune announced in its
+// header, and that a failed write is reported
+func TestExportWAV(t *testing.T) {
+	tune := ym3Tune(50)
+	path := t.TempDir() + "/tune.wav"
+	if err := ExportWAV(tune, defaultSampleRate, path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) < 44 || string(data[:4]) != "RIFF" {
+		t.Fatalf("file of %d bytes isn't a WAV file", len(data))
+	}
+	if dataSize := binary.LittleEndian.Uint32(data[40:]); int(dataSize) != len(data)-44 || dataSize != 4*defaultSampleRate {
+		t.Errorf("header gives %d bytes of samples, file holds %d, want %d", dataSize, len(data)-44, 4*defaultSampleRate)
+	}
+
+	player, err := loadTune(tune, defaultSampleRate, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer player.Destroy()
+	if err := writeWAV(failingWriter{}, player, defaultSampleRate, defaultSampleRate); err == nil {
+		t.Error("writeWAV reported no error on a failing writer")
+	}
+}
+
+func TestNewYMPlayerUnsupported(t *testing.T) {
+	if _, err := NewYMPlayer([]byte("RIFF\x00\x00\x00\x00WAVE"), defaultSampleRate, false); err == nil {
+		t.Error("NewYMPlayer accepted a WAV file")
+	}
+}
+
+// corruptTune returns the embedded tune with the start of its packed stream zeroed,
+// where the LZH decoder reads its Huffman tables
+func corruptTune() []byte {
+	data := append([]byte(nil), musicData...)
+	start := int(data[0]) + 2 // Header size, plus the size and checksum bytes
+	for i := start; i < start+6; i++ {
+		data[i] = 0
+	}
+	return data
+}
+
+// TestNewYMPlayerCorrupt checks that a corrupt archive, on which stsound panics, is
+// reported as an error instead of crashing the demo
+func TestNewYMPlayerCorrupt(t *testing.T) {
+	if _, err := NewYMPlayer(corruptTune(), defaultSampleRate, true); err == nil {
+		t.Error("NewYMPlayer accepted a corrupt archive")
+	}
+	if err := ExportWAV(corruptTune(), defaultSampleRate, t.TempDir()+"/tune.wav"); err == nil {
+		t.Error("ExportWAV accepted a corrupt archive")
+	}
+}
+
+// TestReadFailure checks that a failure ends the stream instead of returning an error,
+// which would stop the whole game, and that Err reports it
+func TestReadFailure(t *testing.T) {
+	y, err := NewYMPlayer(musicData, defaultSampleRate, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 4096)
+	if _, err := y.Read(buf); err != nil {
+		t.Fatalf("Read failed on the embedded tune: %v", err)
+	}
+	if err := y.Err(); err != nil {
+		t.Fatalf("Err reported %v while the tune plays", err)
+	}
+
+	// A failed reload leaves the player without an engine, like Close
+	y.Close()
+	if _, err := y.Read(buf); err != io.EOF {
+		t.Errorf("Read without an engine returned %v, want io.EOF", err)
+	}
+	if y.Err() == nil {
+		t.Error("Err reported nothing after the engine was gone")
+	}
+}
+
+// TestResampleLength checks that the resampler turns n engine samples into n·out/in
+// output samples, within one, both up and down. The engine always renders at
+// ymNativeRate, so the other direction overrides the rates the resampler works from.
+func TestResampleLength(t *testing.T) {
+	const n = 44100 // Engine samples to consume
+
+	for _, rates := range []struct{ in, out int }{{44100, 48000}, {48000, 44100}} {
+		y, err := NewYMPlayer(musicData, rates.out, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rates.in != ymNativeRate {
+			y.nativeRate = rates.in
+			y.step = float64(rates.in) / float64(rates.out)
+		}
+
+		// Render one sample at a time, counting the engine samples the resampler moves past
+		consumed, produced := 0, 0
+		last := y.nativePos
+		sample := make([]int16, 1)
+		for consumed < n {
+			y.render(sample)
+			produced++
+			consumed += (y.nativePos - last + len(y.native)) % len(y.native)
+			last = y.nativePos
+		}
+		y.Close()
+
+		want := float64(n) * float64(rates.out) / float64(rates.in)
+		if math.Abs(float64(produced)-want) > 1 {
+			t.Errorf("%d to %d Hz: %d engine samples gave %d output samples, want %.1f ± 1", rates.in, rates.out, n, produced, want)
+		}
+	}
+}
+
+// ym3Tune returns a raw YM3 tune of the given number of 50 Hz frames, holding a steady
+// tone on channel A. The registers are stored one after the other, each for every frame.
+func ym3Tune(frames int) []byte {
+	regs := make([]byte, ymRegisters*frames)
+	for i := 0; i < frames; i++ {
+		regs[0*frames+i] = 0x1c  // Channel A period
+		regs[7*frames+i] = 0x3e  // Mixer: tone on channel A only
+		regs[8*frames+i] = 15    // Channel A volume
+		regs[13*frames+i] = 0xff // Envelope shape left alone
+	}
+	return append([]byte("YM3!"), regs...)
+}
+
+// readToEnd reads y until the end of the stream and returns the number of samples read,
+// failing once it has read more than limit samples
+func readToEnd(t *testing.T, y *YMPlayer, limit int64) int64 {
+	t.Helper()
+	buf := make([]byte, 4096)
+	var samples int64
+	for {
+		n, err := y.Read(buf)
+		samples += int64(n / 4)
+		if err == io.EOF {
+			return samples
+		}
+		if err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+		if samples > limit {
+			t.Fatalf("no end after %d samples", samples)
+		}
+	}
+}
+
+// TestLoopLimit checks that a looping tune limited to two loops plays about twice, then
+// ends. The loop is found in the chunk completing it, so the count is within a chunk
+// and the buffer the end falls in.
+func TestLoopLimit(t *testing.T) {
+	y, err := NewYMPlayer(ym3Tune(100), defaultSampleRate, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer y.Close()
+	y.SetLoopLimit(2)
+
+	want := 2 * y.totalSamples
+	samples := readToEnd(t, y, 4*want)
+	if tolerance := int64(len(y.buffer) + 1024); samples < want-tolerance || samples > want+tolerance {
+		t.Errorf("2 loops gave %d samples, want %d ± %d", samples, want, tolerance)
+	}
+}